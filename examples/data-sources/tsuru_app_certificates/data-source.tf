@@ -0,0 +1,7 @@
+data "tsuru_app_certificates" "app01" {
+  app = "app01"
+}
+
+output "cnames_without_tls" {
+  value = [for item in data.tsuru_app_certificates.app01.certificates : item.id if !item.tls_active]
+}
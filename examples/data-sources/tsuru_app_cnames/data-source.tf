@@ -0,0 +1,15 @@
+data "tsuru_apps" "tls" {
+  tags = ["tls"]
+}
+
+data "tsuru_app_cnames" "tls" {
+  apps = data.tsuru_apps.tls.names
+}
+
+resource "tsuru_certificate_issuer" "tls" {
+  for_each = { for item in data.tsuru_app_cnames.tls.cnames : item.id => item }
+
+  app    = each.value.app
+  cname  = each.value.cname
+  issuer = "lets-encrypt"
+}
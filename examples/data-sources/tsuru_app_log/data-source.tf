@@ -0,0 +1,5 @@
+data "tsuru_app_log" "my-app" {
+  app     = "sample-app"
+  process = "web"
+  lines   = 50
+}
@@ -0,0 +1,19 @@
+data "tsuru_app_service_instance_binds" "app01" {
+  app = "app01"
+}
+
+# adopt every existing bind of the app, requires terraform >= 1.7
+import {
+  for_each = { for bind in data.tsuru_app_service_instance_binds.app01.binds : bind.id => bind }
+
+  to = tsuru_service_instance_bind.app01[each.key]
+  id = each.key
+}
+
+resource "tsuru_service_instance_bind" "app01" {
+  for_each = { for bind in data.tsuru_app_service_instance_binds.app01.binds : bind.id => bind }
+
+  service_name     = each.value.service_name
+  service_instance = each.value.service_instance
+  app              = "app01"
+}
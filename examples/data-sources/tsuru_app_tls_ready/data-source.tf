@@ -0,0 +1,8 @@
+data "tsuru_app_tls_ready" "app01" {
+  app    = "app01"
+  issuer = "lets-encrypt"
+}
+
+output "cnames_pending_tls" {
+  value = data.tsuru_app_tls_ready.app01.not_ready_cnames
+}
@@ -0,0 +1,4 @@
+data "tsuru_apps" "tls" {
+  tags = ["tls"]
+  pool = "prod"
+}
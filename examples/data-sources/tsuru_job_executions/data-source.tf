@@ -0,0 +1,8 @@
+data "tsuru_job_executions" "backup" {
+  job   = tsuru_job.backup.name
+  limit = 5
+}
+
+output "last_backup_status" {
+  value = try(data.tsuru_job_executions.backup.executions[0].status, "never ran")
+}
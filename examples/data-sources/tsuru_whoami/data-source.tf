@@ -0,0 +1,5 @@
+data "tsuru_whoami" "current" {}
+
+output "tsuru_identity" {
+  value = data.tsuru_whoami.current.email
+}
@@ -18,5 +18,8 @@ resource "tsuru_app" "my-app" {
     }
   }
 
-  restart_on_update = true
+  tracking_annotations = {
+    "managed-by" = "terraform"
+    "workspace"  = terraform.workspace
+  }
 }
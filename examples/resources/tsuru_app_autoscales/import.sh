@@ -0,0 +1,4 @@
+terraform import tsuru_app_autoscales.resource_name "app"
+
+# example
+terraform import tsuru_app_autoscales.my-app "sample-app"
@@ -0,0 +1,22 @@
+resource "tsuru_app_autoscales" "my-app" {
+  app = tsuru_app.my-app.name
+
+  process {
+    name        = "web"
+    min_units   = 3
+    max_units   = 10
+    cpu_average = "60%"
+  }
+
+  process {
+    name      = "worker"
+    min_units = 1
+    max_units = 5
+
+    prometheus {
+      name      = "queue-size"
+      threshold = 100
+      query     = "sum(queue_messages{app=\"my-app\"})"
+    }
+  }
+}
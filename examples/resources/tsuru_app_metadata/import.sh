@@ -0,0 +1,4 @@
+terraform import tsuru_app_metadata.resource_name "app"
+
+# example
+terraform import tsuru_app_metadata.my-app "sample-app"
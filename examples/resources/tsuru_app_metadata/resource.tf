@@ -0,0 +1,11 @@
+resource "tsuru_app_metadata" "my-app" {
+  app = "sample-app"
+
+  labels = {
+    "team" = "platform"
+  }
+
+  annotations = {
+    "docs" = "https://docs.example.com"
+  }
+}
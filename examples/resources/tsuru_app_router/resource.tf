@@ -6,3 +6,24 @@ resource "tsuru_app_router" "other-router" {
     "key" = "value"
   }
 }
+
+resource "tsuru_app_router" "public-router" {
+  app  = tsuru_app.my-app.name
+  name = "ingress-nginx"
+
+  rate_limit {
+    requests_per_second = 50
+    burst_multiplier    = 3
+    connections         = 20
+  }
+}
+
+resource "tsuru_app_router" "alb-router" {
+  app  = tsuru_app.my-app.name
+  name = "aws-alb"
+
+  health_check {
+    path            = "/healthz"
+    expected_status = "200-299"
+  }
+}
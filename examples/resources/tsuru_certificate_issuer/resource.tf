@@ -0,0 +1,15 @@
+resource "tsuru_certificate_issuer" "my-cert" {
+  app    = "sample-app"
+  cname  = "sample-app.mydomain.com"
+  issuer = "lets-encrypt"
+}
+
+# HTTP-01 issuers only issue certificates after the cname points to tsuru,
+# use a DNS-01 issuer to have the certificate ready before the DNS cutover
+resource "tsuru_certificate_issuer" "pre-cutover-cert" {
+  app            = "sample-app"
+  cname          = "new.mydomain.com"
+  issuer         = "lets-encrypt-dns01"
+  wait_for_ready = true
+  expect_http01  = false
+}
@@ -0,0 +1,4 @@
+terraform import tsuru_certificate_issuers.resource_name "app::cname"
+
+# example
+terraform import tsuru_certificate_issuers.my-cert "sample-app::sample-app.mydomain.com"
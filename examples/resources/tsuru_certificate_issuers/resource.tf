@@ -0,0 +1,6 @@
+resource "tsuru_certificate_issuers" "my-cert" {
+  app             = "sample-app"
+  cname           = "sample-app.mydomain.com"
+  issuers         = ["lets-encrypt", "zerossl", "internal-ca"]
+  attempt_timeout = "15m"
+}
@@ -0,0 +1,3 @@
+resource "tsuru_cluster_default" "default" {
+  cluster = "my-cluster"
+}
@@ -11,3 +11,20 @@ resource "tsuru_job" "my-job" {
     command = ["echo", "hello"]
   }
 }
+
+resource "tsuru_job" "migration" {
+  name       = "sample-migration"
+  plan       = "c0.1m0.1"
+  team_owner = "admin"
+  pool       = "staging"
+
+  container {
+    image   = "tsuru/scratch:latest"
+    command = ["./migrate.sh"]
+  }
+
+  # runs again every time the release changes
+  triggers = {
+    release = var.release
+  }
+}
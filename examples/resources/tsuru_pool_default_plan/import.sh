@@ -0,0 +1,4 @@
+terraform import tsuru_pool_default_plan.resource_name "pool"
+
+# example
+terraform import tsuru_pool_default_plan.my-pool-default-plan "my-pool"
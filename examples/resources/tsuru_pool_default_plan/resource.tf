@@ -0,0 +1,4 @@
+resource "tsuru_pool_default_plan" "my-pool-default-plan" {
+  pool = "my-pool"
+  plan = "c1m1"
+}
@@ -0,0 +1,7 @@
+resource "tsuru_token_regen" "simple_token_rotation" {
+  token_id = "my-simple-token"
+
+  triggers = {
+    rotation = "2024-q1"
+  }
+}
@@ -0,0 +1,146 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
+)
+
+func dataSourceTsuruAppCertificates() *schema.Resource {
+	return &schema.Resource{
+		Description: "TLS state of the cnames of a tsuru application on each router",
+		ReadContext: dataSourceTsuruAppCertificatesRead,
+		Schema: map[string]*schema.Schema{
+			"app": {
+				Type:        schema.TypeString,
+				Description: "Application name",
+				Required:    true,
+			},
+			"routers": {
+				Type:        schema.TypeList,
+				Description: "Names of the routers to look at, defaults to all routers of the app",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"certificates": {
+				Type:        schema.TypeList,
+				Description: "TLS state of each cname on each router, sorted by router and cname, empty when the app has no TLS configured",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Description: "Stable identifier of the entry, formatted as `router::cname`, suitable as `for_each` key",
+							Computed:    true,
+						},
+						"router": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"cname": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"tls_active": {
+							Type:        schema.TypeBool,
+							Description: "Whether the router serves a certificate for the cname",
+							Computed:    true,
+						},
+						"issuer": {
+							Type:        schema.TypeString,
+							Description: "Issuer of the certificate, empty for certificates set directly",
+							Computed:    true,
+						},
+						"expires_at": {
+							Type:        schema.TypeString,
+							Description: "Expiration date of the certificate, in RFC3339 format, empty when TLS is not active",
+							Computed:    true,
+						},
+						"dns_names": {
+							Type:        schema.TypeList,
+							Description: "DNS names covered by the certificate (SANs)",
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceTsuruAppCertificatesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	provider := meta.(*tsuruProvider)
+	appName := d.Get("app").(string)
+
+	certificates, _, err := provider.TsuruClient.AppApi.AppGetCertificates(ctx, appName)
+	if err != nil {
+		if isNotFoundError(err) {
+			return diag.Errorf("app %s not found", appName)
+		}
+		return diag.Errorf("unable to read certificates of app %s: %v", appName, err)
+	}
+
+	certificates = filterCertificateRouters(certificates, d.Get("routers").([]interface{}))
+
+	d.SetId(appName)
+	d.Set("certificates", flattenAppCertificates(appName, certificates))
+
+	return nil
+}
+
+func flattenAppCertificates(appName string, certificates tsuru.AppCertificates) []interface{} {
+	routerNames := []string{}
+	for routerName := range certificates.Routers {
+		routerNames = append(routerNames, routerName)
+	}
+	sort.Strings(routerNames)
+
+	result := []interface{}{}
+	for _, routerName := range routerNames {
+		cnames := []string{}
+		for cname := range certificates.Routers[routerName].Cnames {
+			cnames = append(cnames, cname)
+		}
+		sort.Strings(cnames)
+
+		for _, cname := range cnames {
+			cnameInRouter := certificates.Routers[routerName].Cnames[cname]
+			item := map[string]interface{}{
+				"id":         createID([]string{routerName, cname}),
+				"router":     routerName,
+				"cname":      cname,
+				"tls_active": false,
+				"issuer":     cnameInRouter.Issuer,
+				"expires_at": "",
+				"dns_names":  []string{},
+			}
+
+			if cnameInRouter.Certificate != "" {
+				cert, err := parseLeafCertificate(cnameInRouter.Certificate)
+				if err != nil {
+					log.Printf("[WARN] unable to parse certificate of cname %s on router %s of app %s: %v", cname, routerName, appName, err)
+				} else {
+					item["tls_active"] = true
+					item["expires_at"] = cert.NotAfter.UTC().Format(time.RFC3339)
+					if dnsNames, err := certificateDNSNames(cnameInRouter.Certificate); err == nil {
+						item["dns_names"] = dnsNames
+					}
+				}
+			}
+
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,118 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
+)
+
+func TestAccDatasourceTsuruAppCertificates_basic(t *testing.T) {
+	certificate := testGenerateCertificatePEM(t, "my-cname.org")
+
+	fakeServer := echo.New()
+	fakeServer.GET("/1.24/apps/:app/certificate", func(c echo.Context) error {
+		if c.Param("app") == "no-tls-app" {
+			return c.JSON(http.StatusOK, tsuru.AppCertificates{})
+		}
+
+		return c.JSON(http.StatusOK, tsuru.AppCertificates{
+			Routers: map[string]tsuru.AppCertificatesRouters{
+				"ingress-router": {
+					Cnames: map[string]tsuru.AppCertificatesCnames{
+						"pending.org":  {Issuer: "lets-encrypt"},
+						"my-cname.org": {Certificate: certificate, Issuer: "lets-encrypt"},
+					},
+				},
+				"another-router": {
+					Cnames: map[string]tsuru.AppCertificatesCnames{
+						"my-cname.org": {Certificate: certificate},
+					},
+				},
+			},
+		})
+	})
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	dataSourceName := "data.tsuru_app_certificates.app01"
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "tsuru_app_certificates" "app01" {
+	app = "app01"
+}
+
+data "tsuru_app_certificates" "ingress" {
+	app     = "app01"
+	routers = ["ingress-router"]
+}
+
+data "tsuru_app_certificates" "no_tls" {
+	app = "no-tls-app"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "certificates.#", "3"),
+					resource.TestCheckResourceAttr(dataSourceName, "certificates.0.id", "another-router::my-cname.org"),
+					resource.TestCheckResourceAttr(dataSourceName, "certificates.0.tls_active", "true"),
+					resource.TestCheckResourceAttr(dataSourceName, "certificates.0.issuer", ""),
+					resource.TestCheckResourceAttr(dataSourceName, "certificates.1.id", "ingress-router::my-cname.org"),
+					resource.TestCheckResourceAttr(dataSourceName, "certificates.1.issuer", "lets-encrypt"),
+					resource.TestCheckResourceAttr(dataSourceName, "certificates.1.dns_names.0", "my-cname.org"),
+					resource.TestCheckResourceAttrSet(dataSourceName, "certificates.1.expires_at"),
+					resource.TestCheckResourceAttr(dataSourceName, "certificates.2.cname", "pending.org"),
+					resource.TestCheckResourceAttr(dataSourceName, "certificates.2.tls_active", "false"),
+					resource.TestCheckResourceAttr("data.tsuru_app_certificates.ingress", "certificates.#", "2"),
+					resource.TestCheckResourceAttr("data.tsuru_app_certificates.no_tls", "certificates.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestFlattenAppCertificates(t *testing.T) {
+	certificate := testGenerateCertificatePEM(t, "b.my-cname.org", "a.my-cname.org")
+
+	result := flattenAppCertificates("app01", tsuru.AppCertificates{
+		Routers: map[string]tsuru.AppCertificatesRouters{
+			"router": {
+				Cnames: map[string]tsuru.AppCertificatesCnames{
+					"b.my-cname.org": {Certificate: certificate, Issuer: "lets-encrypt"},
+					"a.my-cname.org": {Certificate: "invalid"},
+				},
+			},
+		},
+	})
+
+	assert.Len(t, result, 2)
+
+	invalid := result[0].(map[string]interface{})
+	assert.Equal(t, "router::a.my-cname.org", invalid["id"])
+	assert.Equal(t, false, invalid["tls_active"])
+	assert.Equal(t, "", invalid["expires_at"])
+
+	valid := result[1].(map[string]interface{})
+	assert.Equal(t, "router::b.my-cname.org", valid["id"])
+	assert.Equal(t, true, valid["tls_active"])
+	assert.Equal(t, "lets-encrypt", valid["issuer"])
+	assert.Equal(t, []string{"b.my-cname.org", "a.my-cname.org"}, valid["dns_names"])
+	assert.NotEmpty(t, valid["expires_at"])
+
+	assert.Equal(t, []interface{}{}, flattenAppCertificates("app01", tsuru.AppCertificates{}))
+}
@@ -0,0 +1,81 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceTsuruAppCnames() *schema.Resource {
+	return &schema.Resource{
+		Description: "Enumerate the cnames of a set of tsuru applications",
+		ReadContext: dataSourceTsuruAppCnamesRead,
+		Schema: map[string]*schema.Schema{
+			"apps": {
+				Type:        schema.TypeList,
+				Description: "Names of the applications",
+				Required:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"cnames": {
+				Type:        schema.TypeList,
+				Description: "Cnames of the applications, sorted by app and cname",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Description: "Stable identifier of the cname, formatted as `app::cname`, suitable as `for_each` key",
+							Computed:    true,
+						},
+						"app": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"cname": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceTsuruAppCnamesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	provider := meta.(*tsuruProvider)
+
+	appNames := []string{}
+	for _, app := range d.Get("apps").([]interface{}) {
+		appNames = append(appNames, app.(string))
+	}
+	appNames = uniqueSortedStrings(appNames)
+
+	cnames := []map[string]interface{}{}
+	for _, appName := range appNames {
+		app, _, err := provider.TsuruClient.AppApi.AppGet(ctx, appName)
+		if err != nil {
+			return diag.Errorf("unable to read app %s: %v", appName, err)
+		}
+
+		appCnames := uniqueSortedStrings(app.Cname)
+		for _, cname := range appCnames {
+			cnames = append(cnames, map[string]interface{}{
+				"id":    createID([]string{appName, cname}),
+				"app":   appName,
+				"cname": cname,
+			})
+		}
+	}
+
+	d.SetId(createID(appNames))
+	d.Set("cnames", cnames)
+
+	return nil
+}
@@ -0,0 +1,190 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const appLogMaxLines = 1000
+
+type appLogEntry struct {
+	Date    time.Time `json:"Date"`
+	Message string    `json:"Message"`
+	Source  string    `json:"Source"`
+	Unit    string    `json:"Unit"`
+}
+
+func dataSourceTsuruAppLog() *schema.Resource {
+	return &schema.Resource{
+		Description: "Recent log lines of a tsuru application",
+		ReadContext: dataSourceTsuruAppLogRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(1 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"app": {
+				Type:        schema.TypeString,
+				Description: "Application name",
+				Required:    true,
+			},
+			"process": {
+				Type:        schema.TypeString,
+				Description: "Only return log lines of this process",
+				Optional:    true,
+			},
+			"unit": {
+				Type:        schema.TypeString,
+				Description: "Only return log lines of this unit",
+				Optional:    true,
+			},
+			"lines": {
+				Type:         schema.TypeInt,
+				Description:  fmt.Sprintf("Number of most recent log lines to return, up to %d", appLogMaxLines),
+				Optional:     true,
+				Default:      100,
+				ValidateFunc: validation.IntBetween(1, appLogMaxLines),
+			},
+			"messages": {
+				Type:        schema.TypeList,
+				Description: "Messages of the log lines, from the oldest to the most recent",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"entries": {
+				Type:        schema.TypeList,
+				Description: "Log lines, from the oldest to the most recent",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"date": {
+							Type:        schema.TypeString,
+							Description: "Date of the log line, in RFC3339 format",
+							Computed:    true,
+						},
+						"process": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"unit": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"message": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceTsuruAppLogRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	provider := meta.(*tsuruProvider)
+	app := d.Get("app").(string)
+
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutRead))
+	defer cancel()
+
+	entries, err := fetchAppLog(ctx, provider, app, d.Get("process").(string), d.Get("unit").(string), d.Get("lines").(int))
+	if err != nil {
+		return diag.Errorf("unable to read logs of app %s: %v", app, err)
+	}
+
+	messages := []string{}
+	flattened := []map[string]interface{}{}
+	for _, entry := range entries {
+		messages = append(messages, entry.Message)
+		flattened = append(flattened, map[string]interface{}{
+			"date":    entry.Date.Format(time.RFC3339),
+			"process": entry.Source,
+			"unit":    entry.Unit,
+			"message": entry.Message,
+		})
+	}
+
+	d.SetId(app)
+	d.Set("messages", messages)
+	d.Set("entries", flattened)
+
+	return nil
+}
+
+func fetchAppLog(ctx context.Context, provider *tsuruProvider, app, process, unit string, lines int) ([]appLogEntry, error) {
+	values := url.Values{}
+	values.Set("lines", strconv.Itoa(lines))
+	values.Set("follow", "false")
+	if process != "" {
+		values.Set("source", process)
+	}
+	if unit != "" {
+		values.Set("unit", unit)
+	}
+
+	url := fmt.Sprintf("%s/1.0/apps/%s/log?%s", provider.Host, app, values.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	token := provider.Token
+	if token == "" {
+		token = deployToken()
+	}
+	req.Header.Set("Authorization", token)
+
+	resp, err := provider.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return nil, &rawAPIError{StatusCode: resp.StatusCode, Body: body}
+	}
+
+	// the log endpoint streams json encoded lists of log lines, without follow
+	// it sends just the most recent lines, but keep decoding until the end
+	entries := []appLogEntry{}
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var chunk []appLogEntry
+		err = decoder.Decode(&chunk)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, chunk...)
+	}
+
+	if len(entries) > lines {
+		entries = entries[len(entries)-lines:]
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccDatasourceTsuruAppLog_basic(t *testing.T) {
+	fakeServer := echo.New()
+
+	fakeServer.GET("/1.0/apps/:name/log", func(c echo.Context) error {
+		assert.Equal(t, "app01", c.Param("name"))
+		assert.Equal(t, "2", c.QueryParam("lines"))
+		assert.Equal(t, "web", c.QueryParam("source"))
+		assert.Equal(t, "false", c.QueryParam("follow"))
+
+		date := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+		return c.JSON(http.StatusOK, []appLogEntry{
+			{Date: date, Message: "starting server", Source: "web", Unit: "app01-web-1"},
+			{Date: date.Add(time.Second), Message: "listening on :8888", Source: "web", Unit: "app01-web-1"},
+		})
+	})
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDatasourceTsuruAppLogConfig_basic(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.tsuru_app_log.app01", "messages.#", "2"),
+					resource.TestCheckResourceAttr("data.tsuru_app_log.app01", "messages.0", "starting server"),
+					resource.TestCheckResourceAttr("data.tsuru_app_log.app01", "messages.1", "listening on :8888"),
+					resource.TestCheckResourceAttr("data.tsuru_app_log.app01", "entries.1.process", "web"),
+					resource.TestCheckResourceAttr("data.tsuru_app_log.app01", "entries.1.unit", "app01-web-1"),
+					resource.TestCheckResourceAttr("data.tsuru_app_log.app01", "entries.1.date", "2024-03-01T10:00:01Z"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDatasourceTsuruAppLogConfig_basic() string {
+	return `
+	data "tsuru_app_log" "app01" {
+		app     = "app01"
+		process = "web"
+		lines   = 2
+	}
+`
+}
@@ -0,0 +1,96 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"context"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
+)
+
+func dataSourceTsuruAppServiceInstanceBinds() *schema.Resource {
+	return &schema.Resource{
+		Description: "Enumerate the service instances bound to a tsuru application, useful to import existing binds as `tsuru_service_instance_bind` resources",
+		ReadContext: dataSourceTsuruAppServiceInstanceBindsRead,
+		Schema: map[string]*schema.Schema{
+			"app": {
+				Type:        schema.TypeString,
+				Description: "Application name",
+				Required:    true,
+			},
+			"binds": {
+				Type:        schema.TypeList,
+				Description: "Service instances bound to the application, sorted by service and instance",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Description: "ID of the bind, formatted as `service::instance::app`, the same as `tsuru_service_instance_bind` and suitable as import ID and `for_each` key",
+							Computed:    true,
+						},
+						"service_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"service_instance": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"plan": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceTsuruAppServiceInstanceBindsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	provider := meta.(*tsuruProvider)
+	appName := d.Get("app").(string)
+
+	app, _, err := provider.TsuruClient.AppApi.AppGet(ctx, appName)
+	if err != nil {
+		if isNotFoundError(err) {
+			return diag.Errorf("app %s not found", appName)
+		}
+		return diag.Errorf("unable to read app %s: %v", appName, err)
+	}
+
+	d.SetId(appName)
+	d.Set("binds", flattenAppServiceInstanceBinds(appName, app.ServiceInstanceBinds))
+
+	return nil
+}
+
+func flattenAppServiceInstanceBinds(appName string, binds []tsuru.AppServiceInstanceBinds) []interface{} {
+	sorted := make([]tsuru.AppServiceInstanceBinds, len(binds))
+	copy(sorted, binds)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Service != sorted[j].Service {
+			return sorted[i].Service < sorted[j].Service
+		}
+		return sorted[i].Instance < sorted[j].Instance
+	})
+
+	result := []interface{}{}
+	for _, bind := range sorted {
+		result = append(result, map[string]interface{}{
+			"id":               createID([]string{bind.Service, bind.Instance, appName}),
+			"service_name":     bind.Service,
+			"service_instance": bind.Instance,
+			"plan":             bind.Plan,
+		})
+	}
+
+	return result
+}
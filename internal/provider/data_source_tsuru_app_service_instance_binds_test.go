@@ -0,0 +1,75 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	echo "github.com/labstack/echo/v4"
+	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
+)
+
+func TestAccDatasourceTsuruAppServiceInstanceBinds_basic(t *testing.T) {
+	fakeServer := echo.New()
+
+	fakeServer.GET("/1.0/apps/:name", func(c echo.Context) error {
+		switch c.Param("name") {
+		case "app01":
+			return c.JSON(http.StatusOK, &tsuru.App{
+				Name: "app01",
+				ServiceInstanceBinds: []tsuru.AppServiceInstanceBinds{
+					{Service: "rpaas", Instance: "front", Plan: "small"},
+					{Service: "mysql", Instance: "users-db", Plan: "large"},
+					{Service: "mysql", Instance: "orders-db"},
+				},
+			})
+		case "lonely-app":
+			return c.JSON(http.StatusOK, &tsuru.App{Name: "lonely-app"})
+		}
+		return c.JSON(http.StatusNotFound, nil)
+	})
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	dataSourceName := "data.tsuru_app_service_instance_binds.app01"
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "tsuru_app_service_instance_binds" "app01" {
+	app = "app01"
+}
+
+data "tsuru_app_service_instance_binds" "lonely" {
+	app = "lonely-app"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "binds.#", "3"),
+					resource.TestCheckResourceAttr(dataSourceName, "binds.0.id", "mysql::orders-db::app01"),
+					resource.TestCheckResourceAttr(dataSourceName, "binds.1.id", "mysql::users-db::app01"),
+					resource.TestCheckResourceAttr(dataSourceName, "binds.1.plan", "large"),
+					resource.TestCheckResourceAttr(dataSourceName, "binds.2.service_name", "rpaas"),
+					resource.TestCheckResourceAttr(dataSourceName, "binds.2.service_instance", "front"),
+					resource.TestCheckResourceAttr("data.tsuru_app_service_instance_binds.lonely", "binds.#", "0"),
+				),
+			},
+			{
+				Config:      `data "tsuru_app_service_instance_binds" "missing" { app = "missing-app" }`,
+				ExpectError: regexp.MustCompile("app missing-app not found"),
+			},
+		},
+	})
+}
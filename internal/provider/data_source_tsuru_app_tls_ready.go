@@ -0,0 +1,113 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"context"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
+)
+
+func dataSourceTsuruAppTLSReady() *schema.Resource {
+	return &schema.Resource{
+		Description: "Whether all cnames of a tsuru application have their certificates ready on every router",
+		ReadContext: dataSourceTsuruAppTLSReadyRead,
+		Schema: map[string]*schema.Schema{
+			"app": {
+				Type:        schema.TypeString,
+				Description: "Application name",
+				Required:    true,
+			},
+			"issuer": {
+				Type:        schema.TypeString,
+				Description: "Only consider the cnames using this certificate issuer",
+				Optional:    true,
+			},
+			"routers": {
+				Type:        schema.TypeList,
+				Description: "Names of the routers to look at, defaults to all routers of the app",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"ready": {
+				Type:        schema.TypeBool,
+				Description: "Whether the certificates of all considered cnames are ready, also true when no cname is considered",
+				Computed:    true,
+			},
+			"cnames": {
+				Type:        schema.TypeList,
+				Description: "Cnames considered, sorted",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"not_ready_cnames": {
+				Type:        schema.TypeList,
+				Description: "Cnames without a certificate on some router, sorted",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceTsuruAppTLSReadyRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	provider := meta.(*tsuruProvider)
+	appName := d.Get("app").(string)
+
+	certificates, _, err := provider.TsuruClient.AppApi.AppGetCertificates(ctx, appName)
+	if err != nil {
+		if isNotFoundError(err) {
+			return diag.Errorf("app %s not found", appName)
+		}
+		return diag.Errorf("unable to read certificates of app %s: %v", appName, err)
+	}
+
+	certificates = filterCertificateRouters(certificates, d.Get("routers").([]interface{}))
+	cnames, notReady := cnamesTLSReadiness(certificates, d.Get("issuer").(string))
+
+	d.SetId(appName)
+	d.Set("ready", len(notReady) == 0)
+	d.Set("cnames", cnames)
+	d.Set("not_ready_cnames", notReady)
+
+	return nil
+}
+
+// cnamesTLSReadiness returns the cnames using the issuer, all cnames when
+// issuer is empty, and those missing the certificate on any router that
+// reports them.
+func cnamesTLSReadiness(certificates tsuru.AppCertificates, issuer string) ([]string, []string) {
+	issuersByCname := map[string][]string{}
+	for _, router := range certificates.Routers {
+		for cname, cnameInRouter := range router.Cnames {
+			if issuer != "" && cnameInRouter.Issuer != issuer {
+				continue
+			}
+			issuersByCname[cname] = append(issuersByCname[cname], cnameInRouter.Issuer)
+		}
+	}
+
+	cnames := []string{}
+	for cname := range issuersByCname {
+		cnames = append(cnames, cname)
+	}
+	sort.Strings(cnames)
+
+	notReady := []string{}
+	for _, cname := range cnames {
+		for _, cnameIssuer := range uniqueSortedStrings(issuersByCname[cname]) {
+			usedRouters, usedCertificates := certificatesByIssuer(certificates, cname, cnameIssuer)
+			if len(usedCertificates) < len(usedRouters) {
+				notReady = append(notReady, cname)
+				break
+			}
+		}
+	}
+
+	return cnames, notReady
+}
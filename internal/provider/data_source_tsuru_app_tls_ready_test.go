@@ -0,0 +1,103 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
+)
+
+func TestAccDatasourceTsuruAppTLSReady_basic(t *testing.T) {
+	fakeServer := echo.New()
+	fakeServer.GET("/1.24/apps/:app/certificate", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, tsuru.AppCertificates{
+			Routers: map[string]tsuru.AppCertificatesRouters{
+				"ingress-router": {
+					Cnames: map[string]tsuru.AppCertificatesCnames{
+						"pending.org":  {Issuer: "lets-encrypt"},
+						"my-cname.org": {Issuer: "lets-encrypt", Certificate: "123"},
+						"static.org":   {Certificate: "321"},
+					},
+				},
+			},
+		})
+	})
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	dataSourceName := "data.tsuru_app_tls_ready.app01"
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "tsuru_app_tls_ready" "app01" {
+	app = "app01"
+}
+
+data "tsuru_app_tls_ready" "self_signed" {
+	app    = "app01"
+	issuer = "self-signed"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "ready", "false"),
+					resource.TestCheckResourceAttr(dataSourceName, "cnames.#", "3"),
+					resource.TestCheckResourceAttr(dataSourceName, "not_ready_cnames.#", "1"),
+					resource.TestCheckResourceAttr(dataSourceName, "not_ready_cnames.0", "pending.org"),
+					resource.TestCheckResourceAttr("data.tsuru_app_tls_ready.self_signed", "ready", "true"),
+					resource.TestCheckResourceAttr("data.tsuru_app_tls_ready.self_signed", "cnames.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestCnamesTLSReadiness(t *testing.T) {
+	certificates := tsuru.AppCertificates{
+		Routers: map[string]tsuru.AppCertificatesRouters{
+			"ingress-router": {
+				Cnames: map[string]tsuru.AppCertificatesCnames{
+					"my-cname.org": {Issuer: "lets-encrypt", Certificate: "123"},
+					"pending.org":  {Issuer: "lets-encrypt"},
+					"static.org":   {Certificate: "321"},
+				},
+			},
+			"another-router": {
+				Cnames: map[string]tsuru.AppCertificatesCnames{
+					"my-cname.org": {Issuer: "lets-encrypt"},
+					"static.org":   {Certificate: "321"},
+				},
+			},
+		},
+	}
+
+	cnames, notReady := cnamesTLSReadiness(certificates, "")
+	assert.Equal(t, []string{"my-cname.org", "pending.org", "static.org"}, cnames)
+	assert.Equal(t, []string{"my-cname.org", "pending.org"}, notReady)
+
+	cnames, notReady = cnamesTLSReadiness(certificates, "lets-encrypt")
+	assert.Equal(t, []string{"my-cname.org", "pending.org"}, cnames)
+	assert.Equal(t, []string{"my-cname.org", "pending.org"}, notReady)
+
+	cnames, notReady = cnamesTLSReadiness(filterCertificateRouters(certificates, []interface{}{"ingress-router"}), "")
+	assert.Equal(t, []string{"my-cname.org", "pending.org", "static.org"}, cnames)
+	assert.Equal(t, []string{"pending.org"}, notReady)
+
+	cnames, notReady = cnamesTLSReadiness(tsuru.AppCertificates{}, "")
+	assert.Equal(t, []string{}, cnames)
+	assert.Equal(t, []string{}, notReady)
+}
@@ -0,0 +1,153 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"context"
+	"net/http"
+	"sort"
+
+	"github.com/antihax/optional"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
+)
+
+func dataSourceTsuruApps() *schema.Resource {
+	return &schema.Resource{
+		Description: "List tsuru applications, optionally filtered by tags, pool and team owner",
+		ReadContext: dataSourceTsuruAppsRead,
+		Schema: map[string]*schema.Schema{
+			"tags": {
+				Type:        schema.TypeList,
+				Description: "Only list apps that have all these tags",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"pool": {
+				Type:        schema.TypeString,
+				Description: "Only list apps of this pool",
+				Optional:    true,
+			},
+			"team_owner": {
+				Type:        schema.TypeString,
+				Description: "Only list apps owned by this team",
+				Optional:    true,
+			},
+			"names": {
+				Type:        schema.TypeList,
+				Description: "Sorted names of the apps found",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"apps": {
+				Type:        schema.TypeList,
+				Description: "Apps found, sorted by name",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"pool": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"team_owner": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"tags": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"cnames": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceTsuruAppsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	provider := meta.(*tsuruProvider)
+
+	tags := []string{}
+	for _, tag := range d.Get("tags").([]interface{}) {
+		tags = append(tags, tag.(string))
+	}
+
+	opts := &tsuru.AppListOpts{
+		Simplified: optional.NewBool(true),
+	}
+	// the API filters by a single tag, the remaining are matched below
+	if len(tags) > 0 {
+		opts.Tag = optional.NewInterface(tags[0])
+	}
+	if pool := d.Get("pool").(string); pool != "" {
+		opts.Pool = optional.NewString(pool)
+	}
+	if teamOwner := d.Get("team_owner").(string); teamOwner != "" {
+		opts.TeamOwner = optional.NewString(teamOwner)
+	}
+
+	apps, resp, err := provider.TsuruClient.AppApi.AppList(ctx, opts)
+	if err != nil && (resp == nil || resp.StatusCode != http.StatusNoContent) {
+		return diag.Errorf("unable to list apps: %v", err)
+	}
+
+	apps = filterAppsByTags(apps, tags)
+	sort.Slice(apps, func(i, j int) bool {
+		return apps[i].Name < apps[j].Name
+	})
+
+	names := []string{}
+	flattened := []map[string]interface{}{}
+	for _, app := range apps {
+		names = append(names, app.Name)
+		flattened = append(flattened, map[string]interface{}{
+			"name":       app.Name,
+			"pool":       app.Pool,
+			"team_owner": app.TeamOwner,
+			"tags":       app.Tags,
+			"cnames":     uniqueSortedStrings(app.Cname),
+		})
+	}
+
+	d.SetId(createID(append([]string{d.Get("pool").(string), d.Get("team_owner").(string)}, tags...)))
+	d.Set("names", names)
+	d.Set("apps", flattened)
+
+	return nil
+}
+
+func filterAppsByTags(apps []tsuru.MiniApp, tags []string) []tsuru.MiniApp {
+	result := []tsuru.MiniApp{}
+	for _, app := range apps {
+		appTags := map[string]bool{}
+		for _, tag := range app.Tags {
+			appTags[tag] = true
+		}
+
+		matches := true
+		for _, tag := range tags {
+			if !appTags[tag] {
+				matches = false
+				break
+			}
+		}
+
+		if matches {
+			result = append(result, app)
+		}
+	}
+	return result
+}
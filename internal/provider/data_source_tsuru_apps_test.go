@@ -0,0 +1,93 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
+)
+
+func TestAccDatasourceTsuruApps_basic(t *testing.T) {
+	fakeServer := echo.New()
+
+	fakeServer.GET("/1.0/apps", func(c echo.Context) error {
+		assert.Equal(t, "tls", c.QueryParam("tag"))
+		assert.Equal(t, "prod", c.QueryParam("pool"))
+
+		return c.JSON(http.StatusOK, []tsuru.MiniApp{
+			{Name: "app02", Pool: "prod", TeamOwner: "myteam", Tags: []string{"tls", "public"}, Cname: []string{"b.app02.com", "a.app02.com"}},
+			{Name: "app03", Pool: "prod", TeamOwner: "myteam", Tags: []string{"tls"}},
+			{Name: "app01", Pool: "prod", TeamOwner: "otherteam", Tags: []string{"public", "tls"}},
+		})
+	})
+	fakeServer.GET("/1.0/apps/:name", func(c echo.Context) error {
+		name := c.Param("name")
+		apps := map[string]*tsuru.App{
+			"app01": {Name: "app01", Cname: []string{"www.app01.com"}},
+			"app02": {Name: "app02", Cname: []string{"b.app02.com", "a.app02.com"}},
+		}
+		return c.JSON(http.StatusOK, apps[name])
+	})
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDatasourceTsuruAppsConfig_basic(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.tsuru_apps.public", "names.#", "2"),
+					resource.TestCheckResourceAttr("data.tsuru_apps.public", "names.0", "app01"),
+					resource.TestCheckResourceAttr("data.tsuru_apps.public", "names.1", "app02"),
+					resource.TestCheckResourceAttr("data.tsuru_apps.public", "apps.0.team_owner", "otherteam"),
+					resource.TestCheckResourceAttr("data.tsuru_apps.public", "apps.1.cnames.0", "a.app02.com"),
+
+					resource.TestCheckResourceAttr("data.tsuru_app_cnames.public", "cnames.#", "3"),
+					resource.TestCheckResourceAttr("data.tsuru_app_cnames.public", "cnames.0.id", "app01::www.app01.com"),
+					resource.TestCheckResourceAttr("data.tsuru_app_cnames.public", "cnames.1.app", "app02"),
+					resource.TestCheckResourceAttr("data.tsuru_app_cnames.public", "cnames.1.cname", "a.app02.com"),
+					resource.TestCheckResourceAttr("data.tsuru_app_cnames.public", "cnames.2.cname", "b.app02.com"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDatasourceTsuruAppsConfig_basic() string {
+	return `
+	data "tsuru_apps" "public" {
+		tags = ["tls", "public"]
+		pool = "prod"
+	}
+
+	data "tsuru_app_cnames" "public" {
+		apps = data.tsuru_apps.public.names
+	}
+`
+}
+
+func TestFilterAppsByTags(t *testing.T) {
+	apps := []tsuru.MiniApp{
+		{Name: "app01", Tags: []string{"a", "b"}},
+		{Name: "app02", Tags: []string{"b"}},
+		{Name: "app03"},
+	}
+
+	assert.Equal(t, []tsuru.MiniApp{apps[0]}, filterAppsByTags(apps, []string{"b", "a"}))
+	assert.Equal(t, apps[:2], filterAppsByTags(apps, []string{"b"}))
+	assert.Equal(t, apps, filterAppsByTags(apps, nil))
+}
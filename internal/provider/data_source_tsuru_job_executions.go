@@ -0,0 +1,118 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
+)
+
+func dataSourceTsuruJobExecutions() *schema.Resource {
+	return &schema.Resource{
+		Description: "Recent executions of a tsuru job, as kept by the cluster. " +
+			"tsuru API does not report when an execution ended nor the exit code of its container, the status tells whether it succeeded",
+		ReadContext: dataSourceTsuruJobExecutionsRead,
+		Schema: map[string]*schema.Schema{
+			"job": {
+				Type:        schema.TypeString,
+				Description: "Job name",
+				Required:    true,
+			},
+			"limit": {
+				Type:         schema.TypeInt,
+				Description:  "Maximum number of executions to return, the most recent first",
+				Optional:     true,
+				Default:      10,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"executions": {
+				Type:        schema.TypeList,
+				Description: "Executions of the job, the most recent first, empty when the job never ran",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Description: "Name of the execution",
+							Computed:    true,
+						},
+						"status": {
+							Type:        schema.TypeString,
+							Description: "Status of the execution: `started`, `succeeded` or `error`",
+							Computed:    true,
+						},
+						"started_at": {
+							Type:        schema.TypeString,
+							Description: "Date the execution was created, in RFC3339 format",
+							Computed:    true,
+						},
+						"restarts": {
+							Type:        schema.TypeInt,
+							Description: "Number of container restarts during the execution",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceTsuruJobExecutionsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	provider := meta.(*tsuruProvider)
+	name := d.Get("job").(string)
+
+	job, _, err := provider.TsuruClient.JobApi.GetJob(ctx, name)
+	if err != nil {
+		if isNotFoundError(err) {
+			return diag.Errorf("job %s not found", name)
+		}
+		return diag.Errorf("unable to read job %s: %v", name, err)
+	}
+
+	d.SetId(name)
+	d.Set("executions", flattenJobExecutions(job.Units, d.Get("limit").(int)))
+
+	return nil
+}
+
+func flattenJobExecutions(units []tsuru.Unit, limit int) []interface{} {
+	sorted := make([]tsuru.Unit, len(units))
+	copy(sorted, units)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return parseUnitCreatedAt(sorted[i]).After(parseUnitCreatedAt(sorted[j]))
+	})
+
+	if len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+
+	result := []interface{}{}
+	for _, unit := range sorted {
+		result = append(result, map[string]interface{}{
+			"name":       unit.Name,
+			"status":     unit.Status,
+			"started_at": unit.CreatedAt,
+			"restarts":   unitRestarts(unit),
+		})
+	}
+
+	return result
+}
+
+func parseUnitCreatedAt(unit tsuru.Unit) time.Time {
+	createdAt, err := time.Parse(time.RFC3339Nano, unit.CreatedAt)
+	if err != nil {
+		return time.Time{}
+	}
+	return createdAt
+}
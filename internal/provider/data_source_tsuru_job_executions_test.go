@@ -0,0 +1,92 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
+	"k8s.io/utils/ptr"
+)
+
+func TestAccDatasourceTsuruJobExecutions_basic(t *testing.T) {
+	fakeServer := echo.New()
+
+	fakeServer.GET("/1.13/jobs/:name", func(c echo.Context) error {
+		switch c.Param("name") {
+		case "job01":
+			return c.JSON(http.StatusOK, tsuru.JobInfo{
+				Job: tsuru.Job{Name: "job01"},
+				Units: []tsuru.Unit{
+					{Name: "job01-28000000", Status: "error", CreatedAt: "2024-03-01T10:00:00Z", Restarts: ptr.To(2)},
+					{Name: "job01-28000120", Status: "started", CreatedAt: "2024-03-01T12:00:00Z"},
+					{Name: "job01-28000060", Status: "succeeded", CreatedAt: "2024-03-01T11:00:00Z"},
+				},
+			})
+		case "idle-job":
+			return c.JSON(http.StatusOK, tsuru.JobInfo{Job: tsuru.Job{Name: "idle-job"}})
+		}
+		return c.JSON(http.StatusNotFound, nil)
+	})
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "tsuru_job_executions" "job01" {
+	job   = "job01"
+	limit = 2
+}
+
+data "tsuru_job_executions" "idle" {
+	job = "idle-job"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.tsuru_job_executions.job01", "executions.#", "2"),
+					resource.TestCheckResourceAttr("data.tsuru_job_executions.job01", "executions.0.name", "job01-28000120"),
+					resource.TestCheckResourceAttr("data.tsuru_job_executions.job01", "executions.0.status", "started"),
+					resource.TestCheckResourceAttr("data.tsuru_job_executions.job01", "executions.1.name", "job01-28000060"),
+					resource.TestCheckResourceAttr("data.tsuru_job_executions.job01", "executions.1.started_at", "2024-03-01T11:00:00Z"),
+					resource.TestCheckResourceAttr("data.tsuru_job_executions.idle", "executions.#", "0"),
+				),
+			},
+			{
+				Config:      `data "tsuru_job_executions" "missing" { job = "missing-job" }`,
+				ExpectError: regexp.MustCompile("job missing-job not found"),
+			},
+		},
+	})
+}
+
+func TestFlattenJobExecutions(t *testing.T) {
+	units := []tsuru.Unit{
+		{Name: "old", Status: "error", CreatedAt: "2024-03-01T10:00:00Z", Restarts: ptr.To(2)},
+		{Name: "newest", Status: "started", CreatedAt: "2024-03-01T10:00:00.5Z"},
+	}
+
+	executions := flattenJobExecutions(units, 10)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "newest", "status": "started", "started_at": "2024-03-01T10:00:00.5Z", "restarts": 0},
+		map[string]interface{}{"name": "old", "status": "error", "started_at": "2024-03-01T10:00:00Z", "restarts": 2},
+	}, executions)
+
+	assert.Len(t, flattenJobExecutions(units, 1), 1)
+	assert.Equal(t, []interface{}{}, flattenJobExecutions(nil, 10))
+}
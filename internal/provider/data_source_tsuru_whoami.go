@@ -0,0 +1,123 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+	tsuru_client "github.com/tsuru/go-tsuruclient/pkg/tsuru"
+)
+
+func dataSourceTsuruWhoami() *schema.Resource {
+	return &schema.Resource{
+		Description: "Identity authenticated by the token used by the provider",
+		ReadContext: dataSourceTsuruWhoamiRead,
+		Schema: map[string]*schema.Schema{
+			"email": {
+				Type:        schema.TypeString,
+				Description: "Email of the authenticated user or token",
+				Computed:    true,
+			},
+			"teams": {
+				Type:        schema.TypeList,
+				Description: "Teams in which the authenticated identity has roles",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"groups": {
+				Type:        schema.TypeList,
+				Description: "Groups of the authenticated identity",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"roles": {
+				Type:        schema.TypeList,
+				Description: "Roles assigned to the authenticated identity",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"context_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"context_value": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"group": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"permissions": {
+				Type:        schema.TypeList,
+				Description: "Summary of the permissions granted, formatted as `permission(context_type:context_value)`",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceTsuruWhoamiRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	provider := meta.(*tsuruProvider)
+
+	user, _, err := provider.TsuruClient.UserApi.UserGet(ctx)
+	if err != nil {
+		var apiError tsuru_client.GenericOpenAPIError
+		if errors.As(err, &apiError) && (apiError.StatusCode() == http.StatusUnauthorized || apiError.StatusCode() == http.StatusForbidden) {
+			return diag.Errorf("unable to authenticate on tsuru API, check the token used by the provider: %v", err)
+		}
+		return diag.Errorf("unable to read authenticated user: %v", err)
+	}
+
+	teams := []string{}
+	roles := []map[string]interface{}{}
+	for _, role := range user.Roles {
+		if role.Contexttype == "team" && role.Contextvalue != "" {
+			teams = append(teams, role.Contextvalue)
+		}
+		roles = append(roles, map[string]interface{}{
+			"name":          role.Name,
+			"context_type":  role.Contexttype,
+			"context_value": role.Contextvalue,
+			"group":         role.Group,
+		})
+	}
+
+	d.SetId(user.Email)
+	d.Set("email", user.Email)
+	d.Set("teams", uniqueSortedStrings(teams))
+	d.Set("groups", user.Groups)
+	d.Set("roles", roles)
+	d.Set("permissions", summarizePermissions(user.Permissions))
+
+	return nil
+}
+
+func summarizePermissions(permissions []tsuru_client.PermissionUser) []string {
+	summary := []string{}
+	for _, permission := range permissions {
+		name := permission.Name
+		if name == "" {
+			name = "*"
+		}
+		if permission.Contexttype != "" && permission.Contexttype != "global" {
+			name += "(" + permission.Contexttype + ":" + permission.Contextvalue + ")"
+		}
+		summary = append(summary, name)
+	}
+	return uniqueSortedStrings(summary)
+}
@@ -0,0 +1,97 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
+)
+
+func TestAccDatasourceTsuruWhoami_basic(t *testing.T) {
+	fakeServer := echo.New()
+
+	fakeServer.GET("/1.0/users/info", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, &tsuru.User{
+			Email:  "ci@tsuru.io",
+			Groups: []string{"platform"},
+			Roles: []tsuru.RoleUser{
+				{Name: "team-member", Contexttype: "team", Contextvalue: "myteam"},
+				{Name: "team-admin", Contexttype: "team", Contextvalue: "another-team", Group: "platform"},
+				{Name: "team-member", Contexttype: "team", Contextvalue: "myteam"},
+			},
+			Permissions: []tsuru.PermissionUser{
+				{Name: "app.deploy", Contexttype: "team", Contextvalue: "myteam"},
+				{Name: "app.read", Contexttype: "global"},
+			},
+		})
+	})
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `data "tsuru_whoami" "me" {}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.tsuru_whoami.me", "email", "ci@tsuru.io"),
+					resource.TestCheckResourceAttr("data.tsuru_whoami.me", "teams.#", "2"),
+					resource.TestCheckResourceAttr("data.tsuru_whoami.me", "teams.0", "another-team"),
+					resource.TestCheckResourceAttr("data.tsuru_whoami.me", "teams.1", "myteam"),
+					resource.TestCheckResourceAttr("data.tsuru_whoami.me", "groups.0", "platform"),
+					resource.TestCheckResourceAttr("data.tsuru_whoami.me", "roles.#", "3"),
+					resource.TestCheckResourceAttr("data.tsuru_whoami.me", "roles.1.group", "platform"),
+					resource.TestCheckResourceAttr("data.tsuru_whoami.me", "permissions.0", "app.deploy(team:myteam)"),
+					resource.TestCheckResourceAttr("data.tsuru_whoami.me", "permissions.1", "app.read"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDatasourceTsuruWhoami_unauthorized(t *testing.T) {
+	fakeServer := echo.New()
+
+	fakeServer.GET("/1.0/users/info", func(c echo.Context) error {
+		return c.String(http.StatusUnauthorized, "invalid token")
+	})
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      `data "tsuru_whoami" "me" {}`,
+				ExpectError: regexp.MustCompile("unable to authenticate on tsuru API"),
+			},
+		},
+	})
+}
+
+func TestSummarizePermissions(t *testing.T) {
+	summary := summarizePermissions([]tsuru.PermissionUser{
+		{Name: "app", Contexttype: "pool", Contextvalue: "prod"},
+		{Name: "", Contexttype: "global"},
+		{Name: "app", Contexttype: "pool", Contextvalue: "prod"},
+	})
+	assert.Equal(t, []string{"*", "app(pool:prod)"}, summary)
+}
@@ -0,0 +1,158 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"bufio"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+const (
+	// tsuru writes a keep alive message to the deploy stream every 30 seconds
+	// of silence, so a longer silence means the stream was dropped
+	defaultDeployStreamIdleTimeout = 2 * time.Minute
+	defaultDeployStreamKeepAlive   = 30 * time.Second
+
+	deployStreamKeepAliveMessage = "please wait..."
+)
+
+var errDeployStreamIdle = errors.New("no data received from deploy stream")
+
+type deployStreamSettings struct {
+	IdleTimeout time.Duration
+	KeepAlive   time.Duration
+}
+
+func deployStreamSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Description: "Settings of the connection streaming the deploy log, used when wait is enabled. When the stream drops, the deploy is followed through its event",
+		Optional:    true,
+		MaxItems:    1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"idle_timeout": {
+					Type:         schema.TypeString,
+					Description:  "Maximum time without receiving data before the stream is considered dropped, like `2m`, defaults to `2m`",
+					Optional:     true,
+					ValidateFunc: validateDuration,
+				},
+				"keep_alive": {
+					Type:         schema.TypeString,
+					Description:  "Interval between TCP keep-alive probes of the connection, like `30s`, defaults to `30s`",
+					Optional:     true,
+					ValidateFunc: validateDuration,
+				},
+			},
+		},
+	}
+}
+
+func expandDeployStreamSettings(raw interface{}) deployStreamSettings {
+	settings := deployStreamSettings{
+		IdleTimeout: defaultDeployStreamIdleTimeout,
+		KeepAlive:   defaultDeployStreamKeepAlive,
+	}
+
+	items, ok := raw.([]interface{})
+	if !ok || len(items) == 0 || items[0] == nil {
+		return settings
+	}
+
+	m := items[0].(map[string]interface{})
+	if v, ok := m["idle_timeout"].(string); ok && v != "" {
+		settings.IdleTimeout, _ = time.ParseDuration(v)
+	}
+	if v, ok := m["keep_alive"].(string); ok && v != "" {
+		settings.KeepAlive, _ = time.ParseDuration(v)
+	}
+
+	return settings
+}
+
+func (s deployStreamSettings) httpClient(provider *tsuruProvider) *http.Client {
+	transport := provider.Transport
+	transport.KeepAlive = s.KeepAlive
+
+	return newHTTPClient(transport)
+}
+
+// consumeDeployStream logs the deploy stream until it ends, it returns the
+// number of log lines received, keep alive messages are not counted because
+// they are not stored in the event log.
+func consumeDeployStream(body io.ReadCloser, idleTimeout time.Duration) (int, error) {
+	reader := newIdleTimeoutReader(body, idleTimeout)
+	defer reader.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := scanner.Text()
+		log.Println("[DEBUG]", line)
+		if line != deployStreamKeepAliveMessage {
+			lines++
+		}
+	}
+
+	return lines, scanner.Err()
+}
+
+// logEventFrom logs the lines of the event log after the given offset and
+// returns the new offset.
+func logEventFrom(eventLog string, offset int) int {
+	lines := strings.Split(strings.TrimRight(eventLog, "\n"), "\n")
+	if eventLog == "" {
+		lines = nil
+	}
+
+	for i := offset; i < len(lines); i++ {
+		log.Println("[DEBUG]", lines[i])
+	}
+
+	if len(lines) > offset {
+		return len(lines)
+	}
+	return offset
+}
+
+type idleTimeoutReader struct {
+	body     io.ReadCloser
+	timeout  time.Duration
+	timer    *time.Timer
+	timedOut atomic.Bool
+}
+
+func newIdleTimeoutReader(body io.ReadCloser, timeout time.Duration) *idleTimeoutReader {
+	r := &idleTimeoutReader{body: body, timeout: timeout}
+	r.timer = time.AfterFunc(timeout, func() {
+		r.timedOut.Store(true)
+		r.body.Close()
+	})
+	return r
+}
+
+func (r *idleTimeoutReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	if n > 0 {
+		r.timer.Reset(r.timeout)
+	}
+	if err != nil && r.timedOut.Load() {
+		return n, errDeployStreamIdle
+	}
+	return n, err
+}
+
+func (r *idleTimeoutReader) Close() error {
+	r.timer.Stop()
+	return r.body.Close()
+}
@@ -0,0 +1,56 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumeDeployStream(t *testing.T) {
+	body := io.NopCloser(strings.NewReader("---- Pulling image ----\nplease wait...\n---- Deploying ----\nOK\n"))
+
+	lines, err := consumeDeployStream(body, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 3, lines)
+}
+
+func TestConsumeDeployStreamIdle(t *testing.T) {
+	reader, writer := io.Pipe()
+	defer writer.Close()
+
+	go func() {
+		writer.Write([]byte("---- Pulling image ----\nplease wait...\n"))
+	}()
+
+	lines, err := consumeDeployStream(reader, 50*time.Millisecond)
+	assert.Equal(t, errDeployStreamIdle, err)
+	assert.Equal(t, 1, lines)
+}
+
+func TestLogEventFrom(t *testing.T) {
+	assert.Equal(t, 0, logEventFrom("", 0))
+	assert.Equal(t, 3, logEventFrom("line 1\nline 2\nline 3\n", 1))
+	assert.Equal(t, 5, logEventFrom("line 1\nline 2\n", 5))
+}
+
+func TestExpandDeployStreamSettings(t *testing.T) {
+	assert.Equal(t, deployStreamSettings{
+		IdleTimeout: defaultDeployStreamIdleTimeout,
+		KeepAlive:   defaultDeployStreamKeepAlive,
+	}, expandDeployStreamSettings([]interface{}{}))
+
+	assert.Equal(t, deployStreamSettings{
+		IdleTimeout: 5 * time.Minute,
+		KeepAlive:   defaultDeployStreamKeepAlive,
+	}, expandDeployStreamSettings([]interface{}{
+		map[string]interface{}{"idle_timeout": "5m", "keep_alive": ""},
+	}))
+}
@@ -7,20 +7,18 @@ package provider
 import (
 	"bufio"
 	"context"
-	"crypto/tls"
 	"fmt"
 	"io"
 	"log"
-	"net"
 	"net/http"
 	"os"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/tsuru/go-tsuruclient/pkg/client"
 	"github.com/tsuru/go-tsuruclient/pkg/config"
 	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
+	"golang.org/x/oauth2"
 )
 
 func Provider() *schema.Provider {
@@ -28,7 +26,7 @@ func Provider() *schema.Provider {
 		Schema: map[string]*schema.Schema{
 			"host": {
 				Type:        schema.TypeString,
-				Description: "Target to tsuru API",
+				Description: "Target to tsuru API, like `https://tsuru.example.com`, a URL with a path prefix, like `https://proxy.example.com/tsuru`, or a unix socket, like `unix:///var/run/tsuru.sock`",
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("TSURU_HOST", nil),
 			},
@@ -38,6 +36,12 @@ func Provider() *schema.Provider {
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("TSURU_TOKEN", nil),
 			},
+			"token_command": {
+				Type:        schema.TypeString,
+				Description: "Command run with `sh -c` to obtain the token to authenticate on tsuru API, for short-lived credentials issued by auth brokers. Its output, with surrounding whitespace trimmed, is used as token, and the command runs again when tsuru API refuses the token. Takes precedence over `token`",
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("TSURU_TOKEN_COMMAND", nil),
+			},
 			"skip_cert_verification": {
 				Type:        schema.TypeBool,
 				Description: "Disable certificate verification",
@@ -45,6 +49,24 @@ func Provider() *schema.Provider {
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("TSURU_SKIP_CERT_VERIFICATION", nil),
 			},
+			"disable_http2": {
+				Type:        schema.TypeBool,
+				Description: "Use HTTP/1.1 to connect to tsuru API, for gateways that misbehave with HTTP/2. (Default: false)",
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("TSURU_DISABLE_HTTP2", false),
+			},
+			"disable_compression": {
+				Type:        schema.TypeBool,
+				Description: "Do not ask tsuru API for gzip compressed responses, for gateways that misbehave with compression. (Default: false)",
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("TSURU_DISABLE_COMPRESSION", false),
+			},
+			"trace_file": {
+				Type:        schema.TypeString,
+				Description: "Path of a file to append traces of the requests sent to tsuru API and their responses, for debugging. Authorization and cookie headers, credential fields of bodies, like `password`, `token` and `key`, and values of private env vars are redacted, other data is written as is. Disabled by default",
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("TSURU_TRACE_FILE", nil),
+			},
 			"full_management_of_user_environment_variables": {
 				Type:        schema.TypeBool,
 				Description: "Use `true` to manage all user environment variables. (Default: false)",
@@ -52,6 +74,7 @@ func Provider() *schema.Provider {
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("TSURU_FULL_MANAGEMENT_OF_USER_ENVIRONMENT_VARIABLES", nil),
 			},
+			"retry": retrySchema("Retry settings of operations refused by tsuru API because the target is locked by another operation, resources with a `retry` block override them"),
 		},
 		ResourcesMap: map[string]*schema.Resource{
 			"tsuru_service_instance_bind":  resourceTsuruServiceInstanceBind(),
@@ -61,32 +84,46 @@ func Provider() *schema.Provider {
 			"tsuru_volume_bind": resourceTsuruVolumeBind(),
 			"tsuru_volume":      resourceTsuruVolume(),
 
-			"tsuru_app_autoscale": resourceTsuruApplicationAutoscale(),
-			"tsuru_app_env":       resourceTsuruApplicationEnvironment(),
-			"tsuru_app_unit":      resourceTsuruApplicationUnits(),
-			"tsuru_app_cname":     resourceTsuruApplicationCName(),
-			"tsuru_app_router":    resourceTsuruApplicationRouter(),
-			"tsuru_app_grant":     resourceTsuruApplicationGrant(),
-			"tsuru_app_deploy":    resourceTsuruApplicationDeploy(),
-			"tsuru_app":           resourceTsuruApplication(),
+			"tsuru_app_autoscale":  resourceTsuruApplicationAutoscale(),
+			"tsuru_app_autoscales": resourceTsuruApplicationAutoscales(),
+			"tsuru_app_env":        resourceTsuruApplicationEnvironment(),
+			"tsuru_app_unit":       resourceTsuruApplicationUnits(),
+			"tsuru_app_cname":      resourceTsuruApplicationCName(),
+			"tsuru_app_router":     resourceTsuruApplicationRouter(),
+			"tsuru_app_grant":      resourceTsuruApplicationGrant(),
+			"tsuru_app_deploy":     resourceTsuruApplicationDeploy(),
+			"tsuru_app_metadata":   resourceTsuruApplicationMetadata(),
+			"tsuru_app":            resourceTsuruApplication(),
 
-			"tsuru_certificate_issuer": resourceTsuruCertificateIssuer(),
+			"tsuru_certificate_issuer":  resourceTsuruCertificateIssuer(),
+			"tsuru_certificate_issuers": resourceTsuruCertificateIssuers(),
 
 			"tsuru_job":        resourceTsuruJob(),
 			"tsuru_job_env":    resourceTsuruJobEnvironment(),
 			"tsuru_job_deploy": resourceTsuruJobDeploy(),
 
-			"tsuru_router":          resourceTsuruRouter(),
-			"tsuru_plan":            resourceTsuruPlan(),
-			"tsuru_webhook":         resourceTsuruWebhook(),
-			"tsuru_pool_constraint": resourceTsuruPoolConstraint(),
-			"tsuru_pool":            resourceTsuruPool(),
-			"tsuru_cluster_pool":    resourceTsuruClusterPool(),
-			"tsuru_cluster":         resourceTsuruCluster(),
-			"tsuru_token":           resourceTsuruToken(),
+			"tsuru_router":            resourceTsuruRouter(),
+			"tsuru_plan":              resourceTsuruPlan(),
+			"tsuru_webhook":           resourceTsuruWebhook(),
+			"tsuru_pool_constraint":   resourceTsuruPoolConstraint(),
+			"tsuru_pool_default_plan": resourceTsuruPoolDefaultPlan(),
+			"tsuru_pool":              resourceTsuruPool(),
+			"tsuru_cluster_pool":      resourceTsuruClusterPool(),
+			"tsuru_cluster":           resourceTsuruCluster(),
+			"tsuru_cluster_default":   resourceTsuruClusterDefault(),
+			"tsuru_token":             resourceTsuruToken(),
+			"tsuru_token_regen":       resourceTsuruTokenRegen(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"tsuru_app": dataSourceTsuruApp(),
+			"tsuru_app":                        dataSourceTsuruApp(),
+			"tsuru_app_certificates":           dataSourceTsuruAppCertificates(),
+			"tsuru_app_cnames":                 dataSourceTsuruAppCnames(),
+			"tsuru_app_log":                    dataSourceTsuruAppLog(),
+			"tsuru_app_service_instance_binds": dataSourceTsuruAppServiceInstanceBinds(),
+			"tsuru_app_tls_ready":              dataSourceTsuruAppTLSReady(),
+			"tsuru_apps":                       dataSourceTsuruApps(),
+			"tsuru_job_executions":             dataSourceTsuruJobExecutions(),
+			"tsuru_whoami":                     dataSourceTsuruWhoami(),
 		},
 	}
 	p.ConfigureContextFunc = func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
@@ -99,8 +136,11 @@ func Provider() *schema.Provider {
 type tsuruProvider struct {
 	Host               string
 	Token              string
+	HTTPClient         *http.Client
+	Transport          transportSettings
 	TsuruClient        *tsuru.APIClient
 	FullManagementEnvs bool
+	Retry              retrySettings
 }
 
 func providerConfigure(ctx context.Context, d *schema.ResourceData, terraformVersion string) (interface{}, diag.Diagnostics) {
@@ -111,27 +151,6 @@ func providerConfigure(ctx context.Context, d *schema.ResourceData, terraformVer
 		UserAgent:     userAgent,
 	}
 
-	if d.Get("skip_cert_verification").(bool) {
-		transport := &http.Transport{
-			Proxy: http.ProxyFromEnvironment,
-			DialContext: (&net.Dialer{
-				Timeout:   30 * time.Second,
-				KeepAlive: 30 * time.Second,
-			}).DialContext,
-			ForceAttemptHTTP2:     true,
-			MaxIdleConns:          100,
-			IdleConnTimeout:       90 * time.Second,
-			TLSHandshakeTimeout:   10 * time.Second,
-			ExpectContinueTimeout: 1 * time.Second,
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
-		}
-		cfg.HTTPClient = &http.Client{
-			Transport: transport,
-		}
-	}
-
 	var err error
 	host := d.Get("host").(string)
 	if host == "" {
@@ -144,10 +163,39 @@ func providerConfigure(ctx context.Context, d *schema.ResourceData, terraformVer
 		}
 	}
 
-	cfg.BasePath = host
-	os.Setenv("TSURU_TARGET", host)
+	basePath, socketPath, err := parseTsuruHost(host)
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+
+	transport := transportSettings{
+		SkipCertVerification: d.Get("skip_cert_verification").(bool),
+		SocketPath:           socketPath,
+		DisableHTTP2:         d.Get("disable_http2").(bool),
+		DisableCompression:   d.Get("disable_compression").(bool),
+	}
+	if traceFile := d.Get("trace_file").(string); traceFile != "" {
+		transport.Trace, err = newHTTPTracer(traceFile)
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+	}
 
 	token := d.Get("token").(string)
+	if tokenCommand := d.Get("token_command").(string); tokenCommand != "" {
+		transport.TokenCommand = newTokenCommand(tokenCommand)
+		token, err = transport.TokenCommand.Token(ctx)
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+	}
+
+	httpClient := newHTTPClient(transport)
+	cfg.HTTPClient = httpClient
+
+	cfg.BasePath = basePath
+	os.Setenv("TSURU_TARGET", host)
+
 	if token != "" {
 		cfg.DefaultHeader["Authorization"] = token
 	}
@@ -157,13 +205,27 @@ func providerConfigure(ctx context.Context, d *schema.ResourceData, terraformVer
 		return nil, diag.FromErr(err)
 	}
 
+	// OIDC tokens replace the http client, keep using the configured transport
+	if oauthTransport, ok := cfg.HTTPClient.Transport.(*oauth2.Transport); ok {
+		oauthTransport.Base = httpClient.Transport
+		httpClient = cfg.HTTPClient
+	}
+
 	fullManagementEnvs := d.Get("full_management_of_user_environment_variables").(bool)
 
+	retry := retrySettings{}
+	if settings, ok := expandRetrySettings(d.Get("retry")); ok {
+		retry = *settings
+	}
+
 	return &tsuruProvider{
-		Host:               host,
+		Host:               basePath,
 		Token:              token,
+		HTTPClient:         httpClient,
+		Transport:          transport,
 		TsuruClient:        client,
 		FullManagementEnvs: fullManagementEnvs,
+		Retry:              retry,
 	}, nil
 }
 
@@ -6,11 +6,13 @@ package provider
 
 import (
 	"context"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/pkg/errors"
 	tsuru_client "github.com/tsuru/go-tsuruclient/pkg/tsuru"
 )
@@ -47,6 +49,12 @@ func resourceTsuruApplication() *schema.Resource {
 				Description: "Platform",
 				Required:    true,
 			},
+			"platform_version": {
+				Type:         schema.TypeString,
+				Description:  "Version of the platform image, like `v2`, keeps the app on a previous version of the platform to roll back a platform update, `latest` follows the newest version. Takes effect on the next deploy",
+				Optional:     true,
+				ValidateFunc: validation.StringDoesNotContainAny(":"),
+			},
 			"plan": {
 				Type:        schema.TypeString,
 				Description: "Plan",
@@ -59,7 +67,7 @@ func resourceTsuruApplication() *schema.Resource {
 			},
 			"cluster": {
 				Type:        schema.TypeString,
-				Description: "The name of cluster",
+				Description: "The name of cluster, tsuru places apps on the cluster that serves their pool, so change `pool` to move the app to another cluster",
 				Computed:    true,
 			},
 			"pool": {
@@ -69,13 +77,21 @@ func resourceTsuruApplication() *schema.Resource {
 			},
 			"tags": {
 				Type:        schema.TypeList,
-				Description: "Tags",
+				Description: "Tags, compared with the tags stored by tsuru ignoring surrounding spaces, empty and duplicated tags",
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"metadata": appMetadataSchema(),
+			"tracking_annotations": {
+				Type:        schema.TypeMap,
+				Description: "Annotations written to the app to identify it as managed by terraform, like `managed-by`, `workspace` or `module`, merged with the annotations of `metadata`. Their removal outside of terraform shows as drift",
 				Optional:    true,
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
 			},
-			"metadata": metadataSchema(),
 			"process": {
 				Type:     schema.TypeList,
 				Optional: true,
@@ -103,11 +119,25 @@ func resourceTsuruApplication() *schema.Resource {
 				Type:        schema.TypeBool,
 				Description: "Restart app after applying changes",
 				Optional:    true,
+				Deprecated:  "restart_on_update = false has no effect, use no_restart instead",
+			},
+			"no_restart": {
+				Type:        schema.TypeBool,
+				Description: "Apply in-place updates, like plan and process changes, without restarting the app, they take effect on the next restart or deploy. By default the app is restarted, like tsuru CLI does",
+				Optional:    true,
+				Default:     false,
+			},
+			"wait_for_healthy": {
+				Type:        schema.TypeBool,
+				Description: "Wait for the units to be ready after a change of the plan of the app or of its processes, which recreates the units, before completing the update. Units not ready within the update timeout are reported as errors",
+				Optional:    true,
+				Default:     false,
 			},
 
 			"internal_address": {
-				Type:     schema.TypeList,
-				Computed: true,
+				Type:        schema.TypeList,
+				Description: "Addresses exposed by the processes inside the cluster, ports and protocols of each process are declared in the `kubernetes` section of the tsuru.yaml of the deployed image",
+				Computed:    true,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"domain": {
@@ -134,6 +164,61 @@ func resourceTsuruApplication() *schema.Resource {
 				},
 			},
 
+			"status": {
+				Type:        schema.TypeString,
+				Description: "Overall status of the units of the app: `started` when all units are ready, `stopped` when there are no units running, `error` when a unit failed and `starting` otherwise",
+				Computed:    true,
+			},
+
+			"units_summary": {
+				Type:        schema.TypeList,
+				Description: "Number of units of each process, sorted by process",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"process": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"ready_units": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"total_units": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+
+			"service_instances": {
+				Type:        schema.TypeList,
+				Description: "Service instances bound to the app, sorted by service and instance",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Description: "ID of the bind, formatted as `service::instance::app`, the same as `tsuru_service_instance_bind`",
+							Computed:    true,
+						},
+						"service_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"service_instance": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"plan": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+
 			"router": {
 				Type:     schema.TypeList,
 				Computed: true,
@@ -182,11 +267,19 @@ func metadataSchema() *schema.Schema {
 	}
 }
 
+// appMetadataSchema is the metadata of the app itself, whose ownership may be
+// shared with tsuru_app_metadata.
+func appMetadataSchema() *schema.Schema {
+	s := metadataSchema()
+	s.Description = "Labels and annotations of the app. When not declared, like after an import, all labels and annotations of the app are read and those set outside of terraform show as drift. When declared, only the declared keys are owned by this resource and the others, like those of `tsuru_app_metadata`, are kept"
+	return s
+}
+
 func resourceTsuruApplicationCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	provider := meta.(*tsuruProvider)
 
-	platform := d.Get("platform").(string)
-	if err := validPlatform(ctx, provider, platform); err != nil {
+	platform, err := appPlatform(ctx, provider, d)
+	if err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -227,6 +320,11 @@ func resourceTsuruApplicationCreate(ctx context.Context, d *schema.ResourceData,
 		}
 	}
 
+	app.Metadata, err = mergeTrackingAnnotations(app.Metadata, d.Get("tracking_annotations").(map[string]interface{}))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
 	if m, ok := d.GetOk("process"); ok {
 		processes := processesFromResourceData(m)
 		if processes != nil {
@@ -238,7 +336,7 @@ func resourceTsuruApplicationCreate(ctx context.Context, d *schema.ResourceData,
 		app.Description = desc.(string)
 	}
 
-	_, _, err := provider.TsuruClient.AppApi.AppCreate(ctx, app)
+	_, _, err = provider.TsuruClient.AppApi.AppCreate(ctx, app)
 	if err != nil {
 		return diag.Errorf("unable to create app %s: %v", app.Name, err)
 	}
@@ -251,8 +349,8 @@ func resourceTsuruApplicationCreate(ctx context.Context, d *schema.ResourceData,
 func resourceTsuruApplicationUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	provider := meta.(*tsuruProvider)
 	name := d.Get("name").(string)
-	platform := d.Get("platform").(string)
-	if err := validPlatform(ctx, provider, platform); err != nil {
+	platform, err := appPlatform(ctx, provider, d)
+	if err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -279,7 +377,7 @@ func resourceTsuruApplicationUpdate(ctx context.Context, d *schema.ResourceData,
 		Tags:      tags,
 	}
 
-	if d.HasChange("metadata") {
+	if d.HasChanges("metadata", "tracking_annotations") {
 		old, new := d.GetChange("metadata")
 		oldMetadata := metadataFromResourceData(old)
 		if oldMetadata == nil {
@@ -290,6 +388,15 @@ func resourceTsuruApplicationUpdate(ctx context.Context, d *schema.ResourceData,
 			newMetadata = &tsuru_client.Metadata{}
 		}
 
+		// previous tracking annotations were accepted before, only the new
+		// ones may conflict with metadata
+		oldTracking, newTracking := d.GetChange("tracking_annotations")
+		*oldMetadata, _ = mergeTrackingAnnotations(*oldMetadata, oldTracking.(map[string]interface{}))
+		*newMetadata, err = mergeTrackingAnnotations(*newMetadata, newTracking.(map[string]interface{}))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
 		app.Metadata = tsuru_client.Metadata{
 			Annotations: markRemovedMetadataItemAsDeleted(oldMetadata.Annotations, newMetadata.Annotations),
 			Labels:      markRemovedMetadataItemAsDeleted(oldMetadata.Labels, newMetadata.Labels),
@@ -314,11 +421,7 @@ func resourceTsuruApplicationUpdate(ctx context.Context, d *schema.ResourceData,
 		app.Description = desc.(string)
 	}
 
-	restart := true
-	if r, ok := d.GetOk("restart_on_update"); ok {
-		restart = r.(bool)
-	}
-
+	restart := !d.Get("no_restart").(bool)
 	if !restart {
 		app.NoRestart = true
 	}
@@ -331,9 +434,42 @@ func resourceTsuruApplicationUpdate(ctx context.Context, d *schema.ResourceData,
 	defer resp.Body.Close()
 	logTsuruStream(resp.Body)
 
+	// units are only recreated by a plan change when the app is restarted
+	if d.Get("wait_for_healthy").(bool) && restart && appPlanChanged(d) {
+		_, diags := waitForAppUnitsReady(ctx, provider, name, 0, d.Timeout(schema.TimeoutUpdate))
+		if diags.HasError() {
+			return append(diags, resourceTsuruApplicationRead(ctx, d, meta)...)
+		}
+	}
+
 	return resourceTsuruApplicationRead(ctx, d, meta)
 }
 
+func appPlanChanged(d *schema.ResourceData) bool {
+	if d.HasChange("plan") {
+		return true
+	}
+
+	if !d.HasChange("process") {
+		return false
+	}
+
+	old, new := d.GetChange("process")
+	onlyInOld, onlyInNew, inBoth := checkProcessesListsChanges(processesFromResourceData(old), processesFromResourceData(new))
+	for _, process := range append(onlyInOld, onlyInNew...) {
+		if process.Plan != "$default" {
+			return true
+		}
+	}
+	for _, process := range inBoth {
+		if process.Old.Plan != process.New.Plan {
+			return true
+		}
+	}
+
+	return false
+}
+
 func resourceTsuruApplicationRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	provider := meta.(*tsuruProvider)
 	name := d.Id()
@@ -348,7 +484,15 @@ func resourceTsuruApplicationRead(ctx context.Context, d *schema.ResourceData, m
 	}
 
 	d.Set("name", name)
-	d.Set("platform", app.Platform)
+	// the version is only split out of the platform when managed by
+	// platform_version, the platform may be declared with its version too
+	if _, ok := d.GetOk("platform_version"); ok {
+		platform, version := splitPlatformVersion(app.Platform)
+		d.Set("platform", platform)
+		d.Set("platform_version", version)
+	} else {
+		d.Set("platform", app.Platform)
+	}
 	d.Set("pool", app.Pool)
 	d.Set("plan", app.Plan.Name)
 	d.Set("team_owner", app.TeamOwner)
@@ -358,16 +502,83 @@ func resourceTsuruApplicationRead(ctx context.Context, d *schema.ResourceData, m
 		d.Set("description", app.Description)
 	}
 
-	d.Set("tags", app.Tags)
+	configuredTags := []string{}
+	for _, item := range d.Get("tags").([]interface{}) {
+		configuredTags = append(configuredTags, item.(string))
+	}
+	d.Set("tags", reconcileTags(configuredTags, app.Tags))
 
-	d.Set("metadata", flattenMetadata(app.Metadata))
+	metadata, trackingAnnotations := splitTrackingAnnotations(app.Metadata, d.Get("tracking_annotations").(map[string]interface{}))
+	d.Set("metadata", flattenMetadata(declaredMetadata(metadata, d.Get("metadata"))))
+	d.Set("tracking_annotations", trackingAnnotations)
 	d.Set("internal_address", flattenInternalAddresses(app.InternalAddresses))
 	d.Set("router", flattenRouters(app.Routers))
 	d.Set("process", flattenProcesses(app.Processes))
+	d.Set("status", appStatus(app))
+	d.Set("units_summary", flattenUnitsSummary(app.Units))
+	d.Set("service_instances", flattenAppServiceInstanceBinds(app.Name, app.ServiceInstanceBinds))
 
 	return nil
 }
 
+func appStatus(app tsuru_client.App) string {
+	if app.Error != "" {
+		return "error"
+	}
+
+	ready, stopped := 0, 0
+	for _, unit := range app.Units {
+		switch {
+		case unit.Status == "error":
+			return "error"
+		case unit.Status == "stopped" || unit.Status == "asleep":
+			stopped++
+		case isUnitReady(unit):
+			ready++
+		}
+	}
+
+	if stopped == len(app.Units) {
+		return "stopped"
+	}
+	if ready == len(app.Units) {
+		return "started"
+	}
+	return "starting"
+}
+
+func flattenUnitsSummary(units []tsuru_client.Unit) []interface{} {
+	summary := map[string]map[string]interface{}{}
+	processes := []string{}
+
+	for _, unit := range units {
+		process, found := summary[unit.Processname]
+		if !found {
+			process = map[string]interface{}{
+				"process":     unit.Processname,
+				"ready_units": 0,
+				"total_units": 0,
+			}
+			summary[unit.Processname] = process
+			processes = append(processes, unit.Processname)
+		}
+
+		process["total_units"] = process["total_units"].(int) + 1
+		if isUnitReady(unit) {
+			process["ready_units"] = process["ready_units"].(int) + 1
+		}
+	}
+
+	sort.Strings(processes)
+
+	result := []interface{}{}
+	for _, process := range processes {
+		result = append(result, summary[process])
+	}
+
+	return result
+}
+
 func resourceTsuruApplicationDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	provider := meta.(*tsuruProvider)
 	name := d.Get("name").(string)
@@ -389,6 +600,8 @@ func resourceTsuruApplicationImport(ctx context.Context, d *schema.ResourceData,
 	}
 
 	d.Set("name", app.Name)
+	d.Set("wait_for_healthy", false)
+	d.Set("no_restart", false)
 	d.SetId(app.Name)
 
 	return []*schema.ResourceData{d}, nil
@@ -433,6 +646,85 @@ func processesFromResourceData(meta interface{}) []tsuru_client.AppProcess {
 	return processes
 }
 
+// declaredMetadata keeps only the labels and annotations declared by the
+// resource, so those set by others, like tsuru_app_metadata, are not deleted on
+// update. Removal of declared items outside of terraform still shows as drift.
+// When nothing is declared, like on import, all the metadata is adopted.
+func declaredMetadata(metadata tsuru_client.Metadata, declared interface{}) tsuru_client.Metadata {
+	declaredMetadata := metadataFromResourceData(declared)
+	if declaredMetadata == nil {
+		return metadata
+	}
+
+	result := tsuru_client.Metadata{}
+
+	result.Labels = declaredMetadataItems(metadata.Labels, declaredMetadata.Labels)
+	result.Annotations = declaredMetadataItems(metadata.Annotations, declaredMetadata.Annotations)
+	return result
+}
+
+func declaredMetadataItems(items []tsuru_client.MetadataItem, declared []tsuru_client.MetadataItem) []tsuru_client.MetadataItem {
+	names := map[string]bool{}
+	for _, item := range declared {
+		names[item.Name] = true
+	}
+
+	result := []tsuru_client.MetadataItem{}
+	for _, item := range items {
+		if names[item.Name] {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// mergeTrackingAnnotations appends the tracking annotations, sorted by name,
+// to the annotations of metadata.
+func mergeTrackingAnnotations(metadata tsuru_client.Metadata, trackingAnnotations map[string]interface{}) (tsuru_client.Metadata, error) {
+	if len(trackingAnnotations) == 0 {
+		return metadata, nil
+	}
+
+	for _, annotation := range metadata.Annotations {
+		if _, found := trackingAnnotations[annotation.Name]; found {
+			return metadata, errors.Errorf("annotation %q is set in both metadata and tracking_annotations", annotation.Name)
+		}
+	}
+
+	names := []string{}
+	for name := range trackingAnnotations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	merged := tsuru_client.Metadata{
+		Labels:      metadata.Labels,
+		Annotations: append([]tsuru_client.MetadataItem{}, metadata.Annotations...),
+	}
+	for _, name := range names {
+		merged.Annotations = append(merged.Annotations, tsuru_client.MetadataItem{Name: name, Value: trackingAnnotations[name].(string)})
+	}
+
+	return merged, nil
+}
+
+// splitTrackingAnnotations moves the annotations named in trackingAnnotations
+// out of metadata, those missing on the app are left out so their removal
+// shows as drift.
+func splitTrackingAnnotations(metadata tsuru_client.Metadata, trackingAnnotations map[string]interface{}) (tsuru_client.Metadata, map[string]interface{}) {
+	found := map[string]interface{}{}
+	annotations := []tsuru_client.MetadataItem{}
+	for _, annotation := range metadata.Annotations {
+		if _, tracked := trackingAnnotations[annotation.Name]; tracked {
+			found[annotation.Name] = annotation.Value
+			continue
+		}
+		annotations = append(annotations, annotation)
+	}
+
+	return tsuru_client.Metadata{Labels: metadata.Labels, Annotations: annotations}, found
+}
+
 func metadataFromResourceData(meta interface{}) *tsuru_client.Metadata {
 	m := meta.([]interface{})
 	if len(m) == 0 || m[0] == nil {
@@ -487,6 +779,62 @@ func validPlatform(ctx context.Context, provider *tsuruProvider, platform string
 	return errors.Errorf("invalid platform: %s available platforms are [%s]", platform, plaformList)
 }
 
+// appPlatform returns the platform to send to tsuru API, with the version set
+// in platform_version, if any.
+func appPlatform(ctx context.Context, provider *tsuruProvider, d *schema.ResourceData) (string, error) {
+	platform := d.Get("platform").(string)
+	if err := validPlatform(ctx, provider, platform); err != nil {
+		return "", err
+	}
+
+	version, ok := d.GetOk("platform_version")
+	if !ok {
+		return platform, nil
+	}
+
+	if strings.Contains(platform, ":") {
+		return "", errors.Errorf("platform %s already has a version, set either a versioned platform or platform_version", platform)
+	}
+
+	if version.(string) == "latest" {
+		return platform, nil
+	}
+
+	if err := validPlatformVersion(ctx, provider, platform, version.(string)); err != nil {
+		return "", err
+	}
+
+	return platform + ":" + version.(string), nil
+}
+
+func validPlatformVersion(ctx context.Context, provider *tsuruProvider, platform, version string) error {
+	info, _, err := provider.TsuruClient.PlatformApi.PlatformInfo(ctx, platform)
+	if err != nil {
+		return err
+	}
+
+	availableVersions := []string{}
+	for _, image := range info.Images {
+		_, imageVersion := splitPlatformVersion(image[strings.LastIndex(image, "/")+1:])
+		if imageVersion == version {
+			return nil
+		}
+		availableVersions = append(availableVersions, imageVersion)
+	}
+
+	return errors.Errorf("invalid platform version: %s available versions of platform %s are [%s]", version, platform, strings.Join(availableVersions, ","))
+}
+
+// splitPlatformVersion splits a platform like python:v2 in its name and
+// version, the version is latest when not set.
+func splitPlatformVersion(platform string) (string, string) {
+	parts := strings.SplitN(platform, ":", 2)
+	if len(parts) < 2 || parts[1] == "" {
+		return parts[0], "latest"
+	}
+	return parts[0], parts[1]
+}
+
 func validPool(ctx context.Context, provider *tsuruProvider, pool string) error {
 	pools, _, err := provider.TsuruClient.PoolApi.PoolList(ctx)
 	if err != nil {
@@ -23,7 +23,7 @@ import (
 
 func resourceTsuruApplicationAutoscale() *schema.Resource {
 	return &schema.Resource{
-		Description:   "Tsuru Application Autoscale",
+		Description:   "Tsuru Application Autoscale of a single process, see `tsuru_app_autoscales` to manage the autoscale of all processes of an app at once",
 		CreateContext: resourceTsuruApplicationAutoscaleSet,
 		ReadContext:   resourceTsuruApplicationAutoscaleRead,
 		UpdateContext: resourceTsuruApplicationAutoscaleSet,
@@ -154,6 +154,12 @@ func resourceTsuruApplicationAutoscale() *schema.Resource {
 				Optional:     true,
 				AtLeastOneOf: []string{"cpu_average", "schedule", "prometheus"},
 			},
+			"disabled": {
+				Type:        schema.TypeBool,
+				Description: "Temporarily disable the autoscale of the process, the spec is removed from the app and restored when set back to false",
+				Optional:    true,
+				Default:     false,
+			},
 		},
 	}
 }
@@ -220,19 +226,19 @@ func resourceTsuruApplicationAutoscaleSet(ctx context.Context, d *schema.Resourc
 		autoscale.Behavior.ScaleDown = scaleDown
 	}
 
-	err = resource.RetryContext(ctx, d.Timeout(schema.TimeoutCreate), func() *resource.RetryError {
-		_, err = provider.TsuruClient.AppApi.AutoScaleAdd(ctx, app, autoscale)
+	if d.Get("disabled").(bool) {
+		log.Printf("[INFO] autoscale of %s %s is disabled, removing its spec", app, process)
+		err = removeAppAutoscale(ctx, provider, app, process, d.Timeout(schema.TimeoutCreate))
 		if err != nil {
-			var apiError tsuru_client.GenericOpenAPIError
-			if errors.As(err, &apiError) {
-				if isRetryableError(apiError.Body()) {
-					return resource.RetryableError(err)
-				}
-			}
-			return resource.NonRetryableError(errors.Errorf("Unable to create autoscale %s %s: %v", app, process, err))
+			return diag.FromErr(err)
 		}
-		return nil
-	})
+
+		d.SetId(createID([]string{app, process}))
+
+		return resourceTsuruApplicationAutoscaleRead(ctx, d, meta)
+	}
+
+	err = addAppAutoscale(ctx, provider, app, autoscale, d.Timeout(schema.TimeoutCreate))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -252,55 +258,18 @@ func resourceTsuruApplicationAutoscaleRead(ctx context.Context, d *schema.Resour
 	app := parts[0]
 	process := parts[1]
 
+	disabled := d.Get("disabled").(bool)
 	currentCPUAverage := d.Get("cpu_average").(string)
-	isMilli := strings.HasSuffix(currentCPUAverage, "m")
-	isPercentage := strings.HasSuffix(currentCPUAverage, "%")
 
-	retryCount := 0
-	maxRetries := 5
+	// a disabled autoscale keeps the declared spec in state to be restored
+	// later, so it is not waited for
+	waitFor := []string{process}
+	if disabled {
+		waitFor = nil
+	}
 
 	_, proposed := d.GetChange("scale_down")
-	// autoscale info reflects near realtime
-	err = resource.RetryContext(ctx, d.Timeout(schema.TimeoutCreate), func() *resource.RetryError {
-		retryCount++
-		autoscales, _, err := provider.TsuruClient.AppApi.AutoScaleInfo(ctx, app)
-		if err != nil {
-			return resource.NonRetryableError(errors.Wrapf(err, "unable to read autoscale %s %s", app, process))
-		}
-
-		for _, autoscale := range autoscales {
-			if autoscale.Process != process {
-				continue
-			}
-
-			d.Set("app", app)
-			d.Set("process", autoscale.Process)
-			d.Set("min_units", autoscale.MinUnits)
-			d.Set("max_units", autoscale.MaxUnits)
-			if isPercentage {
-				d.Set("cpu_average", milliToPercentage(autoscale.AverageCPU)+"%")
-			} else if isMilli {
-				d.Set("cpu_average", autoscale.AverageCPU)
-			} else if strings.HasSuffix(autoscale.AverageCPU, "m") {
-				d.Set("cpu_average", milliToPercentage(autoscale.AverageCPU))
-			} else if currentCPUAverage != "" {
-				d.Set("cpu_average", autoscale.AverageCPU)
-			}
-
-			d.Set("schedule", flattenSchedules(autoscale.Schedules))
-			d.Set("prometheus", flattenPrometheus(autoscale.Prometheus, d))
-			d.Set("scale_down", flattenScaleDown(autoscale.Behavior.ScaleDown, proposed))
-			return nil
-		}
-
-		if retryCount >= maxRetries {
-			return resource.NonRetryableError(&MaxRetriesError{Message: fmt.Sprintf("Unable to read autoscale for %s::%s (after %d retries)", app, process, maxRetries)})
-		}
-
-		log.Print("[INFO] no autoscales found, trying again")
-		return resource.RetryableError(fmt.Errorf("unable to read autoscale for %s::%s: process not found", app, process))
-	})
-
+	autoscales, err := waitForAppAutoscales(ctx, provider, app, waitFor, d.Timeout(schema.TimeoutCreate))
 	if err != nil {
 		var mrErr *MaxRetriesError
 		if errors.As(err, &mrErr) {
@@ -316,6 +285,23 @@ func resourceTsuruApplicationAutoscaleRead(ctx context.Context, d *schema.Resour
 		return diag.FromErr(err)
 	}
 
+	for _, autoscale := range autoscales {
+		if autoscale.Process != process {
+			continue
+		}
+
+		d.Set("app", app)
+		d.Set("process", autoscale.Process)
+		d.Set("min_units", autoscale.MinUnits)
+		d.Set("max_units", autoscale.MaxUnits)
+		d.Set("cpu_average", flattenCPUAverage(currentCPUAverage, autoscale.AverageCPU))
+
+		d.Set("schedule", flattenSchedules(autoscale.Schedules))
+		d.Set("prometheus", flattenPrometheus(autoscale.Prometheus, d.Get("prometheus").([]interface{})))
+		d.Set("scale_down", flattenScaleDown(autoscale.Behavior.ScaleDown, proposed))
+		d.Set("disabled", false)
+	}
+
 	return nil
 }
 
@@ -325,9 +311,74 @@ func resourceTsuruApplicationAutoscaleDelete(ctx context.Context, d *schema.Reso
 	app := d.Get("app").(string)
 	process := d.Get("process").(string)
 
-	err := resource.RetryContext(ctx, d.Timeout(schema.TimeoutDelete), func() *resource.RetryError {
+	err := removeAppAutoscale(ctx, provider, app, process, d.Timeout(schema.TimeoutDelete))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func addAppAutoscale(ctx context.Context, provider *tsuruProvider, app string, autoscale tsuru_client.AutoScaleSpec, timeout time.Duration) error {
+	return resource.RetryContext(ctx, timeout, func() *resource.RetryError {
+		_, err := provider.TsuruClient.AppApi.AutoScaleAdd(ctx, app, autoscale)
+		if err != nil {
+			var apiError tsuru_client.GenericOpenAPIError
+			if errors.As(err, &apiError) {
+				if isRetryableError(apiError.Body()) {
+					return resource.RetryableError(err)
+				}
+			}
+			return resource.NonRetryableError(errors.Errorf("Unable to create autoscale %s %s: %v", app, autoscale.Process, err))
+		}
+		return nil
+	})
+}
+
+// waitForAppAutoscales reads the autoscales of the app until all processes
+// are found, autoscale info reflects near realtime. A *MaxRetriesError is
+// returned when some process is still missing after a few reads.
+func waitForAppAutoscales(ctx context.Context, provider *tsuruProvider, app string, processes []string, timeout time.Duration) ([]tsuru_client.AutoScaleSpec, error) {
+	var autoscales []tsuru_client.AutoScaleSpec
+	retryCount := 0
+	maxRetries := 5
+
+	err := resource.RetryContext(ctx, timeout, func() *resource.RetryError {
+		retryCount++
+		var err error
+		autoscales, _, err = provider.TsuruClient.AppApi.AutoScaleInfo(ctx, app)
+		if err != nil {
+			return resource.NonRetryableError(errors.Wrapf(err, "unable to read autoscale of app %s", app))
+		}
+
+		found := map[string]bool{}
+		for _, autoscale := range autoscales {
+			found[autoscale.Process] = true
+		}
+		for _, process := range processes {
+			if found[process] {
+				continue
+			}
+			if retryCount >= maxRetries {
+				return resource.NonRetryableError(&MaxRetriesError{Message: fmt.Sprintf("Unable to read autoscale for %s::%s (after %d retries)", app, process, maxRetries)})
+			}
+			log.Printf("[INFO] autoscale of %s %s not found, trying again", app, process)
+			return resource.RetryableError(fmt.Errorf("unable to read autoscale for %s::%s: process not found", app, process))
+		}
+
+		return nil
+	})
+
+	return autoscales, err
+}
+
+func removeAppAutoscale(ctx context.Context, provider *tsuruProvider, app, process string, timeout time.Duration) error {
+	return resource.RetryContext(ctx, timeout, func() *resource.RetryError {
 		_, err := provider.TsuruClient.AppApi.AutoScaleRemove(ctx, app, process)
 		if err != nil {
+			if isNotFoundError(err) {
+				return nil
+			}
 			var apiError tsuru_client.GenericOpenAPIError
 			if errors.As(err, &apiError) {
 				if isRetryableError(apiError.Body()) {
@@ -338,11 +389,6 @@ func resourceTsuruApplicationAutoscaleDelete(ctx context.Context, d *schema.Reso
 		}
 		return nil
 	})
-	if err != nil {
-		return diag.FromErr(err)
-	}
-
-	return nil
 }
 
 func milliToPercentage(milli string) string {
@@ -358,6 +404,22 @@ func milliToPercentage(milli string) string {
 	return fmt.Sprintf("%.2g", milliInt/10)
 }
 
+// flattenCPUAverage returns the CPU average read from tsuru, which is always
+// in milli, in the same format used by the configuration.
+func flattenCPUAverage(current, read string) string {
+	switch {
+	case strings.HasSuffix(current, "%"):
+		return milliToPercentage(read) + "%"
+	case strings.HasSuffix(current, "m"):
+		return read
+	case strings.HasSuffix(read, "m"):
+		return milliToPercentage(read)
+	case current != "":
+		return read
+	}
+	return current
+}
+
 func schedulesFromResourceData(meta interface{}) []tsuru_client.AutoScaleSchedule {
 	scheduleMeta := meta.([]interface{})
 
@@ -471,12 +533,18 @@ func flattenSchedules(schedules []tsuru_client.AutoScaleSchedule) []interface{}
 	return result
 }
 
-func flattenPrometheus(prometheus []tsuru_client.AutoScalePrometheus, d *schema.ResourceData) []interface{} {
+// flattenPrometheus keeps the custom_address of the declared prometheus
+// blocks, tsuru only returns the address it resolved.
+func flattenPrometheus(prometheus []tsuru_client.AutoScalePrometheus, declared []interface{}) []interface{} {
 	result := []interface{}{}
 
 	for i, prom := range prometheus {
-		customAddressStr := fmt.Sprintf("prometheus.%d.custom_address", i)
-		customAddress := d.Get(customAddressStr).(string)
+		customAddress := ""
+		if i < len(declared) {
+			if declaredProm, ok := declared[i].(map[string]interface{}); ok {
+				customAddress, _ = declaredProm["custom_address"].(string)
+			}
+		}
 		result = append(result, map[string]interface{}{
 			"name":               prom.Name,
 			"threshold":          prom.Threshold,
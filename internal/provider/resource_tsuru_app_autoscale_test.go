@@ -5,6 +5,7 @@
 package provider
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -12,6 +13,7 @@ import (
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	echo "github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
@@ -820,3 +822,106 @@ func testAccResourceTsuruAppAutoscale_scaleDown() string {
 	}
 `
 }
+
+func TestAccResourceTsuruAppAutoscaleDisabled(t *testing.T) {
+	fakeServer := echo.New()
+
+	autoscaleEnabled := false
+	removeCount := 0
+
+	fakeServer.GET("/1.0/apps/:name", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, &tsuru.App{
+			Name:    c.Param("name"),
+			Pool:    "my-pool",
+			Deploys: 2,
+		})
+	})
+
+	fakeServer.GET("/1.9/apps/:app/units/autoscale", func(c echo.Context) error {
+		if autoscaleEnabled {
+			return c.JSON(http.StatusOK, []tsuru.AutoScaleSpec{{
+				Process:    "web",
+				MinUnits:   3,
+				MaxUnits:   10,
+				AverageCPU: "800m",
+			}})
+		}
+		return c.JSON(http.StatusOK, nil)
+	})
+
+	fakeServer.POST("/1.9/apps/:app/units/autoscale", func(c echo.Context) error {
+		autoscale := tsuru.AutoScaleSpec{}
+		c.Bind(&autoscale)
+		assert.Equal(t, "web", autoscale.Process)
+		autoscaleEnabled = true
+		return c.JSON(http.StatusOK, map[string]interface{}{"ok": "true"})
+	})
+
+	fakeServer.DELETE("/1.9/apps/:app/units/autoscale", func(c echo.Context) error {
+		assert.Equal(t, "web", c.QueryParam("process"))
+		autoscaleEnabled = false
+		removeCount++
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	resourceName := "tsuru_app_autoscale.autoscale"
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      nil,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceTsuruAppAutoscale_disabled(false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccResourceExists(resourceName),
+					resource.TestCheckResourceAttr(resourceName, "disabled", "false"),
+					resource.TestCheckResourceAttr(resourceName, "min_units", "3"),
+				),
+			},
+			{
+				Config: testAccResourceTsuruAppAutoscale_disabled(true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccResourceExists(resourceName),
+					resource.TestCheckResourceAttr(resourceName, "disabled", "true"),
+					resource.TestCheckResourceAttr(resourceName, "min_units", "3"),
+					resource.TestCheckResourceAttr(resourceName, "max_units", "10"),
+					func(s *terraform.State) error {
+						assert.False(t, autoscaleEnabled)
+						assert.Equal(t, 1, removeCount)
+						return nil
+					},
+				),
+			},
+			{
+				Config: testAccResourceTsuruAppAutoscale_disabled(false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccResourceExists(resourceName),
+					resource.TestCheckResourceAttr(resourceName, "disabled", "false"),
+					func(s *terraform.State) error {
+						assert.True(t, autoscaleEnabled)
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceTsuruAppAutoscale_disabled(disabled bool) string {
+	return fmt.Sprintf(`
+	resource "tsuru_app_autoscale" "autoscale" {
+		app = "app01"
+		process = "web"
+		min_units = 3
+		max_units = 10
+		cpu_average = "800m"
+		disabled = %t
+	}
+`, disabled)
+}
@@ -0,0 +1,330 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+
+	tsuru_client "github.com/tsuru/go-tsuruclient/pkg/tsuru"
+)
+
+func resourceTsuruApplicationAutoscales() *schema.Resource {
+	return &schema.Resource{
+		Description: "Manage the autoscale of all processes of a tsuru application in a single resource, " +
+			"autoscale of processes not declared here is removed. It is a separate resource because `tsuru_app_autoscale` " +
+			"is identified and imported by a single process and cannot own the processes it does not declare. " +
+			"Do not use it along with `tsuru_app_autoscale` for the same app",
+		CreateContext: resourceTsuruApplicationAutoscalesSet,
+		ReadContext:   resourceTsuruApplicationAutoscalesRead,
+		UpdateContext: resourceTsuruApplicationAutoscalesSet,
+		DeleteContext: resourceTsuruApplicationAutoscalesDelete,
+		CustomizeDiff: resourceTsuruApplicationAutoscalesValidate,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(60 * time.Minute),
+			Update: schema.DefaultTimeout(60 * time.Minute),
+			Delete: schema.DefaultTimeout(60 * time.Minute),
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"app": {
+				Type:        schema.TypeString,
+				Description: "Application name",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"process": {
+				Type:        schema.TypeSet,
+				Description: "Autoscale of a process, identified by its name, each process must be declared only once",
+				Required:    true,
+				MinItems:    1,
+				Set:         autoscaleProcessHash,
+				Elem: &schema.Resource{
+					Schema: autoscaleProcessSchema(),
+				},
+			},
+		},
+	}
+}
+
+// autoscaleProcessSchema reuses the fields of tsuru_app_autoscale, the
+// AtLeastOneOf constraints only work on top level attributes, so they are
+// checked by resourceTsuruApplicationAutoscalesValidate instead.
+func autoscaleProcessSchema() map[string]*schema.Schema {
+	processSchema := map[string]*schema.Schema{
+		"name": {
+			Type:        schema.TypeString,
+			Description: "Application process",
+			Required:    true,
+		},
+	}
+
+	autoscaleSchema := resourceTsuruApplicationAutoscale().Schema
+	for _, key := range []string{"min_units", "max_units", "cpu_average", "schedule", "prometheus", "scale_down"} {
+		field := *autoscaleSchema[key]
+		field.AtLeastOneOf = nil
+		processSchema[key] = &field
+	}
+
+	return processSchema
+}
+
+func autoscaleProcessHash(v interface{}) int {
+	return schema.HashString(v.(map[string]interface{})["name"].(string))
+}
+
+// resourceTsuruApplicationAutoscalesValidate checks the raw configuration,
+// the process set is keyed on the name, so blocks declaring the same process
+// are already merged in the diff.
+func resourceTsuruApplicationAutoscalesValidate(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	config := diff.GetRawConfig()
+	if config.IsNull() || !config.IsKnown() {
+		return nil
+	}
+	return validateAutoscaleProcesses(config.GetAttr("process"))
+}
+
+func validateAutoscaleProcesses(processes cty.Value) error {
+	if processes.IsNull() || !processes.IsKnown() {
+		return nil
+	}
+
+	problems := []string{}
+	seen := map[string]bool{}
+
+	for it := processes.ElementIterator(); it.Next(); {
+		_, block := it.Element()
+		if block.IsNull() || !block.IsKnown() {
+			continue
+		}
+
+		name := ""
+		if nameValue := block.GetAttr("name"); nameValue.IsKnown() && !nameValue.IsNull() {
+			name = nameValue.AsString()
+			if seen[name] {
+				problems = append(problems, fmt.Sprintf("process %q is declared more than once", name))
+			}
+			seen[name] = true
+		}
+
+		cpuAverage, schedule, prometheus := block.GetAttr("cpu_average"), block.GetAttr("schedule"), block.GetAttr("prometheus")
+		if cpuAverage.IsKnown() && schedule.IsKnown() && prometheus.IsKnown() {
+			if (cpuAverage.IsNull() || cpuAverage.AsString() == "") && ctyLength(schedule) == 0 && ctyLength(prometheus) == 0 {
+				problems = append(problems, fmt.Sprintf("process %q: one of `cpu_average,prometheus,schedule` must be specified", name))
+			}
+		}
+
+		minUnits, maxUnits := block.GetAttr("min_units"), block.GetAttr("max_units")
+		if minUnits.IsKnown() && !minUnits.IsNull() && maxUnits.IsKnown() && !maxUnits.IsNull() {
+			if minUnits.AsBigFloat().Cmp(maxUnits.AsBigFloat()) > 0 {
+				problems = append(problems, fmt.Sprintf("process %q: min_units must not be greater than max_units", name))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+func ctyLength(value cty.Value) int {
+	if value.IsNull() {
+		return 0
+	}
+	return value.LengthInt()
+}
+
+func resourceTsuruApplicationAutoscalesSet(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	provider := meta.(*tsuruProvider)
+
+	app := d.Get("app").(string)
+
+	appInfo, _, err := provider.TsuruClient.AppApi.AppGet(ctx, app)
+	if err != nil {
+		if isNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.Errorf("Unable to read app %s: %v", app, err)
+	}
+
+	if appInfo.Deploys == 0 {
+		return diag.Errorf("We can not set a autoscale without a first deploy")
+	}
+
+	timeout := d.Timeout(schema.TimeoutCreate)
+	if !d.IsNewResource() {
+		timeout = d.Timeout(schema.TimeoutUpdate)
+	}
+
+	declared := map[string]bool{}
+	processes := []string{}
+	for _, item := range d.Get("process").(*schema.Set).List() {
+		autoscale := autoscaleSpecFromProcessBlock(item.(map[string]interface{}))
+		declared[autoscale.Process] = true
+		processes = append(processes, autoscale.Process)
+
+		err = addAppAutoscale(ctx, provider, app, autoscale, timeout)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	// processes removed from the configuration or with autoscale set outside
+	// of terraform
+	autoscales, _, err := provider.TsuruClient.AppApi.AutoScaleInfo(ctx, app)
+	if err != nil {
+		return diag.Errorf("unable to read autoscale of app %s: %v", app, err)
+	}
+	for _, autoscale := range autoscales {
+		if declared[autoscale.Process] {
+			continue
+		}
+
+		log.Printf("[INFO] removing autoscale of %s %s, it is not declared anymore", app, autoscale.Process)
+		err = removeAppAutoscale(ctx, provider, app, autoscale.Process, timeout)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	d.SetId(app)
+
+	sort.Strings(processes)
+	_, err = waitForAppAutoscales(ctx, provider, app, processes, timeout)
+	if err != nil {
+		var mrErr *MaxRetriesError
+		if !errors.As(err, &mrErr) {
+			return diag.FromErr(err)
+		}
+		log.Printf("[WARN] %s", mrErr.Message)
+	}
+
+	return resourceTsuruApplicationAutoscalesRead(ctx, d, meta)
+}
+
+func resourceTsuruApplicationAutoscalesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	provider := meta.(*tsuruProvider)
+	app := d.Id()
+
+	autoscales, _, err := provider.TsuruClient.AppApi.AutoScaleInfo(ctx, app)
+	if err != nil {
+		if isNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.Errorf("unable to read autoscale of app %s: %v", app, err)
+	}
+
+	d.Set("app", app)
+	d.Set("process", flattenAutoscaleProcesses(autoscales, d.Get("process").(*schema.Set).List()))
+
+	return nil
+}
+
+func resourceTsuruApplicationAutoscalesDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	provider := meta.(*tsuruProvider)
+	app := d.Get("app").(string)
+
+	for _, item := range d.Get("process").(*schema.Set).List() {
+		process := item.(map[string]interface{})["name"].(string)
+		err := removeAppAutoscale(ctx, provider, app, process, d.Timeout(schema.TimeoutDelete))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return nil
+}
+
+func autoscaleSpecFromProcessBlock(block map[string]interface{}) tsuru_client.AutoScaleSpec {
+	minUnits := block["min_units"].(int)
+	maxUnits := block["max_units"].(int)
+	if minUnits < 0 {
+		minUnits = 1
+	}
+	if maxUnits < 0 {
+		maxUnits = 1
+	}
+	if minUnits > maxUnits {
+		minUnits = maxUnits
+	}
+
+	autoscale := tsuru_client.AutoScaleSpec{
+		Process:    block["name"].(string),
+		MinUnits:   int32(minUnits),
+		MaxUnits:   int32(maxUnits),
+		AverageCPU: block["cpu_average"].(string),
+		Behavior: tsuru_client.AutoScaleSpecBehavior{
+			ScaleDown: scaleDownFromResourceData(block["scale_down"]),
+		},
+	}
+
+	if schedules := schedulesFromResourceData(block["schedule"]); schedules != nil {
+		autoscale.Schedules = schedules
+	}
+
+	if prometheus := prometheusFromResourceData(block["prometheus"]); prometheus != nil {
+		autoscale.Prometheus = prometheus
+	}
+
+	return autoscale
+}
+
+// flattenAutoscaleProcesses returns the autoscale of every process, the
+// declared blocks only keep the format of the values, processes not declared
+// are removed on next apply.
+func flattenAutoscaleProcesses(autoscales []tsuru_client.AutoScaleSpec, declared []interface{}) []interface{} {
+	declaredByName := map[string]map[string]interface{}{}
+	for _, item := range declared {
+		block := item.(map[string]interface{})
+		declaredByName[block["name"].(string)] = block
+	}
+
+	result := []interface{}{}
+	for _, autoscale := range autoscales {
+		block, ok := declaredByName[autoscale.Process]
+		if !ok {
+			block = map[string]interface{}{}
+		}
+		result = append(result, flattenAutoscaleProcess(autoscale, block))
+	}
+
+	return result
+}
+
+func flattenAutoscaleProcess(autoscale tsuru_client.AutoScaleSpec, declared map[string]interface{}) map[string]interface{} {
+	currentCPUAverage, _ := declared["cpu_average"].(string)
+
+	declaredPrometheus, _ := declared["prometheus"].([]interface{})
+
+	proposedScaleDown, ok := declared["scale_down"]
+	if !ok {
+		proposedScaleDown = []interface{}{}
+	}
+
+	return map[string]interface{}{
+		"name":        autoscale.Process,
+		"min_units":   autoscale.MinUnits,
+		"max_units":   autoscale.MaxUnits,
+		"cpu_average": flattenCPUAverage(currentCPUAverage, autoscale.AverageCPU),
+		"schedule":    flattenSchedules(autoscale.Schedules),
+		"prometheus":  flattenPrometheus(autoscale.Prometheus, declaredPrometheus),
+		"scale_down":  flattenScaleDown(autoscale.Behavior.ScaleDown, proposedScaleDown),
+	}
+}
@@ -0,0 +1,345 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
+)
+
+func TestAccResourceTsuruAppAutoscales(t *testing.T) {
+	fakeServer := echo.New()
+
+	var mu sync.Mutex
+	autoscales := map[string]tsuru.AutoScaleSpec{
+		"legacy": {Process: "legacy", MinUnits: 1, MaxUnits: 2, AverageCPU: "500m"},
+	}
+	removed := []string{}
+
+	fakeServer.GET("/1.0/apps/:name", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, &tsuru.App{
+			Name:    c.Param("name"),
+			Deploys: 2,
+		})
+	})
+
+	fakeServer.GET("/1.9/apps/:app/units/autoscale", func(c echo.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		result := []tsuru.AutoScaleSpec{}
+		for _, autoscale := range autoscales {
+			result = append(result, autoscale)
+		}
+		sort.Slice(result, func(i, j int) bool {
+			return result[i].Process < result[j].Process
+		})
+		return c.JSON(http.StatusOK, result)
+	})
+
+	fakeServer.POST("/1.9/apps/:app/units/autoscale", func(c echo.Context) error {
+		autoscale := tsuru.AutoScaleSpec{}
+		err := c.Bind(&autoscale)
+		assert.NoError(t, err)
+		assert.Equal(t, "app01", c.Param("app"))
+
+		// tsuru stores the CPU average in milli
+		if autoscale.AverageCPU == "80%" {
+			autoscale.AverageCPU = "800m"
+		}
+
+		mu.Lock()
+		autoscales[autoscale.Process] = autoscale
+		mu.Unlock()
+		return c.JSON(http.StatusOK, map[string]interface{}{"ok": "true"})
+	})
+
+	fakeServer.DELETE("/1.9/apps/:app/units/autoscale", func(c echo.Context) error {
+		process := c.QueryParam("process")
+
+		mu.Lock()
+		delete(autoscales, process)
+		removed = append(removed, process)
+		mu.Unlock()
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	resourceName := "tsuru_app_autoscales.autoscales"
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      nil,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "tsuru_app_autoscales" "autoscales" {
+	app = "app01"
+
+	process {
+		name        = "web"
+		min_units   = 3
+		max_units   = 10
+		cpu_average = "80%"
+	}
+
+	process {
+		name      = "worker"
+		min_units = 1
+		max_units = 4
+
+		schedule {
+			min_replicas = 2
+			start        = "0 8 * * *"
+			end          = "0 18 * * *"
+		}
+	}
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccResourceExists(resourceName),
+					resource.TestCheckResourceAttr(resourceName, "app", "app01"),
+					resource.TestCheckResourceAttr(resourceName, "process.#", "2"),
+					resource.TestCheckTypeSetElemNestedAttrs(resourceName, "process.*", map[string]string{
+						"name":        "web",
+						"cpu_average": "80%",
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs(resourceName, "process.*", map[string]string{
+						"name":                    "worker",
+						"schedule.0.min_replicas": "2",
+					}),
+					func(s *terraform.State) error {
+						assert.Equal(t, []string{"legacy"}, removed)
+						return nil
+					},
+				),
+			},
+			{
+				Config: `
+resource "tsuru_app_autoscales" "autoscales" {
+	app = "app01"
+
+	process {
+		name        = "web"
+		min_units   = 3
+		max_units   = 12
+		cpu_average = "80%"
+	}
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "process.#", "1"),
+					resource.TestCheckTypeSetElemNestedAttrs(resourceName, "process.*", map[string]string{
+						"name":      "web",
+						"max_units": "12",
+					}),
+					func(s *terraform.State) error {
+						assert.Equal(t, []string{"legacy", "worker"}, removed)
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestResourceTsuruAppAutoscalesSet(t *testing.T) {
+	var mu sync.Mutex
+	autoscales := []tsuru.AutoScaleSpec{}
+
+	fakeServer := echo.New()
+	fakeServer.GET("/1.0/apps/:name", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, &tsuru.App{Name: c.Param("name"), Deploys: 1})
+	})
+	fakeServer.GET("/1.9/apps/:app/units/autoscale", func(c echo.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return c.JSON(http.StatusOK, autoscales)
+	})
+	fakeServer.POST("/1.9/apps/:app/units/autoscale", func(c echo.Context) error {
+		autoscale := tsuru.AutoScaleSpec{}
+		require.NoError(t, c.Bind(&autoscale))
+
+		mu.Lock()
+		defer mu.Unlock()
+		autoscales = append(autoscales, autoscale)
+		sort.Slice(autoscales, func(i, j int) bool {
+			return autoscales[i].Process < autoscales[j].Process
+		})
+		return c.JSON(http.StatusOK, nil)
+	})
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+	server := httptest.NewServer(fakeServer)
+	defer server.Close()
+
+	cfg := tsuru.NewConfiguration()
+	cfg.BasePath = server.URL
+	provider := &tsuruProvider{TsuruClient: tsuru.NewAPIClient(cfg)}
+
+	d := schema.TestResourceDataRaw(t, resourceTsuruApplicationAutoscales().Schema, map[string]interface{}{
+		"app": "app01",
+		"process": []interface{}{
+			map[string]interface{}{"name": "worker", "min_units": 1, "max_units": 4, "cpu_average": "300m"},
+			map[string]interface{}{"name": "web", "min_units": 3, "max_units": 10, "cpu_average": "80%"},
+		},
+	})
+
+	diags := resourceTsuruApplicationAutoscalesSet(context.Background(), d, provider)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "app01", d.Id())
+
+	processes := d.Get("process").(*schema.Set)
+	assert.Equal(t, 2, processes.Len())
+	assert.True(t, processes.Contains(map[string]interface{}{"name": "web"}))
+	assert.True(t, processes.Contains(map[string]interface{}{"name": "worker"}))
+}
+
+func TestAccResourceTsuruAppAutoscalesValidation(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "tsuru_app_autoscales" "autoscales" {
+	app = "app01"
+
+	process {
+		name        = "web"
+		min_units   = 3
+		max_units   = 10
+		cpu_average = "80%"
+	}
+
+	process {
+		name      = "worker"
+		min_units = 5
+		max_units = 2
+	}
+}
+`,
+				ExpectError: regexp.MustCompile(`process "worker": one of ` + "`cpu_average,prometheus,schedule`" + ` must be specified; process "worker": min_units must not be greater than max_units`),
+			},
+			{
+				Config: `
+resource "tsuru_app_autoscales" "autoscales" {
+	app = "app01"
+
+	process {
+		name        = "web"
+		min_units   = 1
+		max_units   = 2
+		cpu_average = "80%"
+	}
+
+	process {
+		name        = "web"
+		min_units   = 1
+		max_units   = 2
+		cpu_average = "50%"
+	}
+}
+`,
+				ExpectError: regexp.MustCompile(`process "web" is declared more than once`),
+			},
+		},
+	})
+}
+
+func TestFlattenAutoscaleProcesses(t *testing.T) {
+	autoscales := []tsuru.AutoScaleSpec{
+		{Process: "legacy", MinUnits: 1, MaxUnits: 2, AverageCPU: "500m"},
+		{Process: "web", MinUnits: 3, MaxUnits: 10, AverageCPU: "800m"},
+		{Process: "worker", MinUnits: 1, MaxUnits: 4, AverageCPU: "300m"},
+	}
+	declared := []interface{}{
+		map[string]interface{}{"name": "worker", "cpu_average": "300m"},
+		map[string]interface{}{"name": "web", "cpu_average": "80%"},
+		map[string]interface{}{"name": "missing", "cpu_average": "10%"},
+	}
+
+	result := flattenAutoscaleProcesses(autoscales, declared)
+	assert.Len(t, result, 3)
+
+	names := []string{}
+	cpus := []string{}
+	for _, item := range result {
+		names = append(names, item.(map[string]interface{})["name"].(string))
+		cpus = append(cpus, item.(map[string]interface{})["cpu_average"].(string))
+	}
+	assert.Equal(t, []string{"legacy", "web", "worker"}, names)
+	assert.Equal(t, []string{"50", "80%", "300m"}, cpus)
+}
+
+func TestFlattenPrometheus(t *testing.T) {
+	prometheus := []tsuru.AutoScalePrometheus{
+		{Name: "requests", Threshold: 10, Query: "sum(rate(requests[1m]))", PrometheusAddress: "http://resolved:9090"},
+		{Name: "errors", Threshold: 1, Query: "sum(rate(errors[1m]))", PrometheusAddress: "http://resolved:9090"},
+	}
+	declared := []interface{}{
+		map[string]interface{}{"name": "requests", "custom_address": "http://custom:9090"},
+	}
+
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "requests", "threshold": float64(10), "query": "sum(rate(requests[1m]))", "custom_address": "http://custom:9090", "prometheus_address": "http://resolved:9090"},
+		map[string]interface{}{"name": "errors", "threshold": float64(1), "query": "sum(rate(errors[1m]))", "custom_address": "", "prometheus_address": "http://resolved:9090"},
+	}, flattenPrometheus(prometheus, declared))
+}
+
+func TestValidateAutoscaleProcesses(t *testing.T) {
+	process := func(name string, minUnits, maxUnits int64, cpuAverage string) cty.Value {
+		cpu := cty.NullVal(cty.String)
+		if cpuAverage != "" {
+			cpu = cty.StringVal(cpuAverage)
+		}
+		return cty.ObjectVal(map[string]cty.Value{
+			"name":        cty.StringVal(name),
+			"min_units":   cty.NumberIntVal(minUnits),
+			"max_units":   cty.NumberIntVal(maxUnits),
+			"cpu_average": cpu,
+			"schedule":    cty.ListValEmpty(cty.EmptyObject),
+			"prometheus":  cty.ListValEmpty(cty.EmptyObject),
+		})
+	}
+
+	assert.NoError(t, validateAutoscaleProcesses(cty.SetVal([]cty.Value{
+		process("web", 1, 2, "80%"),
+		process("worker", 1, 4, "50%"),
+	})))
+
+	err := validateAutoscaleProcesses(cty.SetVal([]cty.Value{
+		process("web", 1, 2, "80%"),
+		process("web", 1, 2, "50%"),
+	}))
+	assert.EqualError(t, err, `process "web" is declared more than once`)
+
+	err = validateAutoscaleProcesses(cty.SetVal([]cty.Value{
+		process("worker", 5, 2, ""),
+	}))
+	assert.EqualError(t, err, `process "worker": one of `+"`cpu_average,prometheus,schedule`"+` must be specified; process "worker": min_units must not be greater than max_units`)
+
+	assert.NoError(t, validateAutoscaleProcesses(cty.UnknownVal(cty.Set(cty.EmptyObject))))
+}
@@ -5,7 +5,6 @@
 package provider
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/base64"
@@ -15,19 +14,34 @@ import (
 	"log"
 	"net/http"
 	"net/url"
+	"regexp"
+	"sort"
 	"strconv"
 	"time"
 
 	"github.com/globalsign/mgo/bson"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
 	tsuruClientConfig "github.com/tsuru/tsuru-client/tsuru/config"
 )
 
+// unitMaxRestarts is how many times a unit of the deployed version may restart
+// before the wait gives up on it instead of waiting for the timeout.
+const unitMaxRestarts = 3
+
+// imageVersionRegexp matches the version tsuru appends to the image it builds
+// for every deploy, like registry/tsuru/app-app01:v3.
+var imageVersionRegexp = regexp.MustCompile(`:v([0-9]+)$`)
+
+// deployCancelTimeout bounds the request canceling an interrupted deploy, it
+// does not depend on the context of the operation, usually already canceled.
+const deployCancelTimeout = 30 * time.Second
+
 func resourceTsuruApplicationDeploy() *schema.Resource {
 	return &schema.Resource{
-		Description:   "Perform an application deploy. Currently, only supporting deploys via prebuilt container images; in order to deploy via tsuru platforms please use tsuru-client",
+		Description:   "Perform an application deploy. Currently, only supporting deploys via prebuilt container images; in order to deploy via tsuru platforms please use tsuru-client. A deploy still running when the operation is interrupted or the resource is destroyed is canceled",
 		CreateContext: resourceTsuruApplicationDeployDo,
 		UpdateContext: resourceTsuruApplicationDeployDo,
 		ReadContext:   resourceTsuruApplicationDeployRead,
@@ -66,7 +80,7 @@ func resourceTsuruApplicationDeploy() *schema.Resource {
 
 			"wait": {
 				Type:        schema.TypeBool,
-				Description: "Wait for the rollout of deploy",
+				Description: "Wait for the rollout of deploy, including the units of the new version to be ready. An app without units, like one scaled to zero, has no units to wait for",
 				Optional:    true,
 				Default:     true,
 			},
@@ -82,6 +96,36 @@ func resourceTsuruApplicationDeploy() *schema.Resource {
 				Description: "Image generated after success of deploy",
 				Computed:    true,
 			},
+
+			"process_readiness": {
+				Type:        schema.TypeList,
+				Description: "Readiness of the units of the deployed version per process, filled when wait is enabled",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"process": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"version": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"ready_units": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"total_units": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+
+			"stream": deployStreamSchema(),
+
+			"retry": retrySchema("Overrides the provider retry settings for the deploy request"),
 		},
 	}
 }
@@ -102,88 +146,336 @@ func resourceTsuruApplicationDeployDo(ctx context.Context, d *schema.ResourceDat
 	values.Set("new-version", strconv.FormatBool(d.Get("new_version").(bool)))
 	values.Set("override-versions", strconv.FormatBool(d.Get("override_old_versions").(bool)))
 
-	var buf bytes.Buffer
-	buf.WriteString(values.Encode())
-
 	url := fmt.Sprintf("%s/1.0/apps/%s/deploy", provider.Host, app)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &buf)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
 	token := provider.Token
 	if token == "" {
 		token = deployToken()
 	}
-	req.Header.Set("Authorization", token)
 
 	wait := d.Get("wait").(bool)
+	stream := expandDeployStreamSettings(d.Get("stream"))
+	client := stream.httpClient(provider)
 
-	resp, err := http.DefaultClient.Do(req)
+	var resp *http.Response
+	err := tsuruRetry(ctx, provider, d, func() error {
+		var buf bytes.Buffer
+		buf.WriteString(values.Encode())
 
-	if err != nil {
-		log.Println("[DEBUG] failed to request deploy", err)
-		return diag.FromErr(err)
-	}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &buf)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Authorization", token)
 
-	if resp.StatusCode != http.StatusOK {
-		body, err := io.ReadAll(resp.Body)
+		resp, err = client.Do(req)
 		if err != nil {
-			return diag.FromErr(err)
+			log.Println("[DEBUG] failed to request deploy", err)
+			return err
 		}
-		return diag.Errorf("Could not deploy, status code: %d, message: %s", resp.StatusCode, string(body))
+
+		if resp.StatusCode != http.StatusOK {
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return err
+			}
+			return &rawAPIError{StatusCode: resp.StatusCode, Body: body}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		var apiError *rawAPIError
+		if errors.As(err, &apiError) {
+			return diag.Errorf("Could not deploy, %v", err)
+		}
+		return diag.FromErr(err)
 	}
 
 	eventID := resp.Header.Get("X-Tsuru-Eventid")
 	d.SetId(eventID)
 
 	if wait {
-		scanner := bufio.NewScanner(resp.Body)
-		for scanner.Scan() {
-			log.Println("[DEBUG]", scanner.Text())
-		}
-
-		if err := scanner.Err(); err != nil {
-			log.Fatal("[ERROR]", err)
+		lines, err := consumeDeployStream(resp.Body, stream.IdleTimeout)
+		logOffset := -1
+		if err != nil {
+			log.Printf("[WARN] deploy stream of event %s interrupted, following the deploy through the event: %v", eventID, err)
+			logOffset = lines
 		}
 
-		err = waitForEventComplete(ctx, provider, eventID)
+		e, err := waitForEventComplete(ctx, provider, eventID, logOffset)
 		if err != nil {
+			if ctx.Err() != nil {
+				return deployInterrupted(provider, eventID, err)
+			}
 			return diag.FromErr(err)
 		}
+
+		timeout := d.Timeout(schema.TimeoutUpdate)
+		if d.IsNewResource() {
+			timeout = d.Timeout(schema.TimeoutCreate)
+		}
+
+		units, diags := waitForAppUnitsReady(ctx, provider, app, deployedVersion(e), timeout)
+		d.Set("process_readiness", flattenProcessReadiness(units))
+		if diags.HasError() {
+			return diags
+		}
 	}
 
 	return resourceTsuruApplicationDeployRead(ctx, d, meta)
 }
 
-func waitForEventComplete(ctx context.Context, provider *tsuruProvider, eventID string) error {
+// waitForEventComplete polls the event until it finishes, when logOffset is
+// not negative the lines of the event log after it are logged as well, to
+// resume a deploy stream that was interrupted.
+func waitForEventComplete(ctx context.Context, provider *tsuruProvider, eventID string, logOffset int) (tsuru.Event, error) {
 	deadline := time.Now().UTC().Add(time.Minute * 2)
 
 	for {
 		e, _, err := provider.TsuruClient.EventApi.EventInfo(ctx, eventID)
 
 		if err != nil {
-			return err
+			return e, err
+		}
+
+		if logOffset >= 0 {
+			logOffset = logEventFrom(e.Log, logOffset)
 		}
 
 		if e.Running {
 			log.Println("[DEBUG] event is still running, pooling in the next 20 seconds")
-			time.Sleep(time.Second * 20)
+			select {
+			case <-ctx.Done():
+				return e, ctx.Err()
+			case <-time.After(time.Second * 20):
+			}
 			continue
 		}
 
 		if e.Error != "" {
-			return errors.New(e.Error + ", see details of event ID: " + eventID)
+			return e, errors.New(e.Error + ", see details of event ID: " + eventID)
 		}
 
 		if time.Now().UTC().After(deadline) {
-			return errors.New("event is still running after deploy")
+			return e, errors.New("event is still running after deploy")
+		}
+
+		return e, nil
+	}
+
+}
+
+// deployInterrupted cancels a deploy whose wait was interrupted, like by
+// Ctrl-C, so it does not keep running on tsuru.
+func deployInterrupted(provider *tsuruProvider, eventID string, err error) diag.Diagnostics {
+	return diag.Diagnostics{
+		{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("deploy %s interrupted", eventID),
+			Detail:   err.Error(),
+		},
+		cancelDeploy(provider, eventID, "deploy interrupted by terraform"),
+	}
+}
+
+// cancelDeploy asks tsuru to cancel the deploy event and reports the outcome
+// as a warning, a deploy that already finished is not cancelable anymore.
+func cancelDeploy(provider *tsuruProvider, eventID, reason string) diag.Diagnostic {
+	ctx, cancel := context.WithTimeout(context.Background(), deployCancelTimeout)
+	defer cancel()
+
+	resp, err := provider.TsuruClient.EventApi.EventCancel(ctx, eventID, tsuru.EventCancelArgs{Reason: reason})
+	if err != nil && (resp == nil || resp.StatusCode != http.StatusNoContent) {
+		return diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("unable to cancel deploy %s", eventID),
+			Detail:   fmt.Sprintf("the deploy may still be running on tsuru: %v", err),
+		}
+	}
+
+	log.Printf("[INFO] deploy %s canceled: %s", eventID, reason)
+	return diag.Diagnostic{
+		Severity: diag.Warning,
+		Summary:  fmt.Sprintf("deploy %s canceled", eventID),
+		Detail:   reason,
+	}
+}
+
+// deployedVersion returns the version created by the deploy event, taken from
+// the image it built, or zero when the event does not tell it.
+func deployedVersion(e tsuru.Event) int32 {
+	data, err := decodeRawBSONMap(e.EndCustomData)
+	if err != nil {
+		return 0
+	}
+
+	image, _ := data["image"].(string)
+	matches := imageVersionRegexp.FindStringSubmatch(image)
+	if matches == nil {
+		return 0
+	}
+
+	version, err := strconv.ParseInt(matches[1], 10, 32)
+	if err != nil {
+		return 0
+	}
+	return int32(version)
+}
+
+// waitForAppUnitsReady waits for the units of version to be ready, when the
+// version is zero the units of the latest version are waited for instead.
+func waitForAppUnitsReady(ctx context.Context, provider *tsuruProvider, appName string, version int32, timeout time.Duration) ([]tsuru.Unit, diag.Diagnostics) {
+	var units []tsuru.Unit
+
+	err := resource.RetryContext(ctx, timeout, func() *resource.RetryError {
+		app, _, err := provider.TsuruClient.AppApi.AppGet(ctx, appName)
+		if err != nil {
+			if isRetryableAPIError(err) {
+				return resource.RetryableError(err)
+			}
+			return resource.NonRetryableError(err)
+		}
+
+		if len(app.Units) == 0 {
+			log.Printf("[DEBUG] app %s has no units, like when scaled to zero, nothing to wait for", appName)
+			units = nil
+			return nil
+		}
+
+		units = deployedVersionUnits(app.Units, version)
+		if len(units) == 0 {
+			log.Printf("[DEBUG] no units of version %d of app %s yet", version, appName)
+			return resource.RetryableError(fmt.Errorf("no units of version %d of app %s", version, appName))
+		}
+
+		notReady := 0
+		for _, unit := range units {
+			if isUnitFailing(unit) {
+				return resource.NonRetryableError(fmt.Errorf("unit %s of app %s is failing", unit.Name, appName))
+			}
+			if !isUnitReady(unit) {
+				notReady++
+			}
+		}
+
+		if notReady > 0 {
+			log.Printf("[DEBUG] %d of %d units of app %s are not ready yet", notReady, len(units), appName)
+			return resource.RetryableError(fmt.Errorf("%d of %d units of app %s are not ready", notReady, len(units), appName))
 		}
 
 		return nil
+	})
+
+	if err == nil {
+		return units, nil
 	}
 
+	diags := diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  fmt.Sprintf("units of app %s are not ready", appName),
+		Detail:   err.Error(),
+	}}
+	for _, unit := range units {
+		if isUnitReady(unit) {
+			continue
+		}
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("unit %s of process %s is not ready", unit.Name, unit.Processname),
+			Detail:   fmt.Sprintf("last status: %q, restarts: %d", unit.Status, unitRestarts(unit)),
+		})
+	}
+
+	return units, diags
+}
+
+func deployedVersionUnits(units []tsuru.Unit, version int32) []tsuru.Unit {
+	if version == 0 {
+		return latestVersionUnits(units)
+	}
+
+	result := []tsuru.Unit{}
+	for _, unit := range units {
+		if unit.Version == version {
+			result = append(result, unit)
+		}
+	}
+	return result
+}
+
+func latestVersionUnits(units []tsuru.Unit) []tsuru.Unit {
+	latestVersion := int32(0)
+	for _, unit := range units {
+		if unit.Version > latestVersion {
+			latestVersion = unit.Version
+		}
+	}
+
+	result := []tsuru.Unit{}
+	for _, unit := range units {
+		if unit.Version == latestVersion {
+			result = append(result, unit)
+		}
+	}
+
+	return result
+}
+
+func isUnitReady(unit tsuru.Unit) bool {
+	if unit.Ready != nil {
+		return *unit.Ready
+	}
+	return unit.Status == "started"
+}
+
+// isUnitFailing tells whether the unit is not going to become ready by
+// waiting longer, like when it is crashing in a loop.
+func isUnitFailing(unit tsuru.Unit) bool {
+	return unit.Status == "error" || unitRestarts(unit) > unitMaxRestarts
+}
+
+func unitRestarts(unit tsuru.Unit) int {
+	if unit.Restarts != nil {
+		return *unit.Restarts
+	}
+	return 0
+}
+
+func flattenProcessReadiness(units []tsuru.Unit) []interface{} {
+	readiness := map[string]map[string]interface{}{}
+	processes := []string{}
+
+	for _, unit := range units {
+		process, found := readiness[unit.Processname]
+		if !found {
+			process = map[string]interface{}{
+				"process":     unit.Processname,
+				"version":     int(unit.Version),
+				"ready_units": 0,
+				"total_units": 0,
+			}
+			readiness[unit.Processname] = process
+			processes = append(processes, unit.Processname)
+		}
+
+		process["total_units"] = process["total_units"].(int) + 1
+		if isUnitReady(unit) {
+			process["ready_units"] = process["ready_units"].(int) + 1
+		}
+	}
+
+	sort.Strings(processes)
+
+	result := []interface{}{}
+	for _, process := range processes {
+		result = append(result, readiness[process])
+	}
+
+	return result
 }
 
 func resourceTsuruApplicationDeployRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -224,8 +516,26 @@ func resourceTsuruApplicationDeployRead(ctx context.Context, d *schema.ResourceD
 }
 
 func resourceTsuruApplicationDeployDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	log.Println("[DEBUG] delete a deploy is a no-op by terraform")
-	return nil
+	return deleteDeploy(ctx, meta.(*tsuruProvider), d.Id())
+}
+
+// deleteDeploy cancels the deploy when it is still running, a finished
+// deploy cannot be undone, so deleting it is a no-op.
+func deleteDeploy(ctx context.Context, provider *tsuruProvider, eventID string) diag.Diagnostics {
+	e, _, err := provider.TsuruClient.EventApi.EventInfo(ctx, eventID)
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil
+		}
+		return diag.Errorf("unable to read deploy %s: %v", eventID, err)
+	}
+
+	if !e.Running {
+		log.Println("[DEBUG] delete a deploy is a no-op by terraform")
+		return nil
+	}
+
+	return diag.Diagnostics{cancelDeploy(provider, eventID, "deploy resource destroyed by terraform")}
 }
 
 func decodeRawBSONMap(input tsuru.EventStartCustomData) (map[string]interface{}, error) {
@@ -5,6 +5,8 @@
 package provider
 
 import (
+	"context"
+	"encoding/base64"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -12,10 +14,17 @@ import (
 	"os"
 	"regexp"
 	"testing"
+	"time"
 
+	"github.com/globalsign/mgo/bson"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	echo "github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
+	"k8s.io/utils/ptr"
 )
 
 func TestAccResourceTsuruAppDeploy(t *testing.T) {
@@ -39,6 +48,18 @@ func TestAccResourceTsuruAppDeploy(t *testing.T) {
 		return c.String(http.StatusOK, "OK")
 	})
 
+	fakeServer.GET("/1.0/apps/:name", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, &tsuru.App{
+			Name: c.Param("name"),
+			Units: []tsuru.Unit{
+				{Name: "app01-web-old", Processname: "web", Version: 1, Status: "started"},
+				{Name: "app01-web-1", Processname: "web", Version: 2, Status: "started", Ready: ptr.To(true)},
+				{Name: "app01-web-2", Processname: "web", Version: 2, Status: "started", Ready: ptr.To(true)},
+				{Name: "app01-worker-1", Processname: "worker", Version: 2, Status: "started"},
+			},
+		})
+	})
+
 	iterationCount := 0
 	fakeServer.GET("/1.1/events/:eventID", func(c echo.Context) error {
 		iterationCount++
@@ -73,6 +94,13 @@ func TestAccResourceTsuruAppDeploy(t *testing.T) {
 					resource.TestCheckResourceAttr(resourceName, "image", "myrepo/app01:0.1.0"),
 					resource.TestCheckResourceAttr(resourceName, "status", "finished"),
 					resource.TestCheckResourceAttr(resourceName, "output_image", "test:1.2.3"),
+					resource.TestCheckResourceAttr(resourceName, "process_readiness.#", "2"),
+					resource.TestCheckResourceAttr(resourceName, "process_readiness.0.process", "web"),
+					resource.TestCheckResourceAttr(resourceName, "process_readiness.0.version", "2"),
+					resource.TestCheckResourceAttr(resourceName, "process_readiness.0.ready_units", "2"),
+					resource.TestCheckResourceAttr(resourceName, "process_readiness.0.total_units", "2"),
+					resource.TestCheckResourceAttr(resourceName, "process_readiness.1.process", "worker"),
+					resource.TestCheckResourceAttr(resourceName, "process_readiness.1.ready_units", "1"),
 				),
 			},
 		},
@@ -128,6 +156,260 @@ func TestAccResourceTsuruAppDeployFailed(t *testing.T) {
 	})
 }
 
+func TestAccResourceTsuruAppDeployUnitsNotReady(t *testing.T) {
+	fakeServer := echo.New()
+
+	fakeServer.POST("/1.0/apps/:app/deploy", func(c echo.Context) error {
+		c.Response().Header().Set("X-Tsuru-Eventid", "abc-123")
+		return c.String(http.StatusOK, "OK")
+	})
+
+	fakeServer.GET("/1.1/events/:eventID", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"Running": false,
+			"EndTime": "2023-01-04T19:26:20.946Z",
+		})
+	})
+
+	fakeServer.GET("/1.0/apps/:name", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, &tsuru.App{
+			Name: c.Param("name"),
+			Units: []tsuru.Unit{
+				{Name: "app01-web-1", Processname: "web", Version: 2, Status: "started", Ready: ptr.To(true)},
+				{Name: "app01-web-2", Processname: "web", Version: 2, Status: "error", Ready: ptr.To(false), Restarts: ptr.To(7)},
+			},
+		})
+	})
+
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      nil,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+	provider "tsuru" {
+		host = "%s"
+	}
+
+	resource "tsuru_app_deploy" "deploy" {
+		app = "app01"
+		image = "myrepo/app01:0.1.0"
+
+		timeouts {
+			create = "3s"
+		}
+	}
+`, server.URL),
+				ExpectError: regexp.MustCompile(`unit app01-web-2 of process web is not ready`),
+			},
+		},
+	})
+}
+
+func TestAccResourceTsuruAppDeployCancelOnDestroy(t *testing.T) {
+	fakeServer := echo.New()
+
+	fakeServer.POST("/1.0/apps/:app/deploy", func(c echo.Context) error {
+		c.Response().Header().Set("X-Tsuru-Eventid", "abc-123")
+		return c.String(http.StatusOK, "OK")
+	})
+
+	canceled := false
+	fakeServer.GET("/1.1/events/:eventID", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"Running": !canceled,
+		})
+	})
+
+	fakeServer.POST("/1.1/events/:eventID/cancel", func(c echo.Context) error {
+		args := tsuru.EventCancelArgs{}
+		err := c.Bind(&args)
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, "abc-123", c.Param("eventID"))
+		assert.Equal(t, "deploy resource destroyed by terraform", args.Reason)
+		canceled = true
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	resourceName := "tsuru_app_deploy.deploy"
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy: func(s *terraform.State) error {
+			if !canceled {
+				return fmt.Errorf("running deploy was not canceled on destroy")
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+	provider "tsuru" {
+		host = "%s"
+	}
+
+	resource "tsuru_app_deploy" "deploy" {
+		app = "app01"
+		image = "myrepo/app01:0.1.0"
+		wait = false
+	}
+`, server.URL),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccResourceExists(resourceName),
+					resource.TestCheckResourceAttr(resourceName, "status", "running"),
+				),
+			},
+		},
+	})
+}
+
+func TestDeployInterrupted(t *testing.T) {
+	fakeServer := echo.New()
+
+	fakeServer.GET("/1.1/events/:eventID", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]interface{}{"Running": true})
+	})
+
+	cancelReason := ""
+	fakeServer.POST("/1.1/events/:eventID/cancel", func(c echo.Context) error {
+		args := tsuru.EventCancelArgs{}
+		err := c.Bind(&args)
+		if err != nil {
+			return err
+		}
+		cancelReason = args.Reason
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	server := httptest.NewServer(fakeServer)
+	defer server.Close()
+
+	cfg := tsuru.NewConfiguration()
+	cfg.BasePath = server.URL
+	provider := &tsuruProvider{TsuruClient: tsuru.NewAPIClient(cfg)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	_, err := waitForEventComplete(ctx, provider, "abc-123", -1)
+	require.ErrorIs(t, err, context.Canceled)
+
+	diags := deployInterrupted(provider, "abc-123", err)
+	require.Len(t, diags, 2)
+	assert.Equal(t, diag.Error, diags[0].Severity)
+	assert.Equal(t, "deploy abc-123 interrupted", diags[0].Summary)
+	assert.Equal(t, diag.Warning, diags[1].Severity)
+	assert.Equal(t, "deploy abc-123 canceled", diags[1].Summary)
+	assert.Equal(t, "deploy interrupted by terraform", cancelReason)
+}
+
+func TestFlattenProcessReadiness(t *testing.T) {
+	units := latestVersionUnits([]tsuru.Unit{
+		{Name: "web-old", Processname: "web", Version: 1, Status: "started"},
+		{Name: "worker-1", Processname: "worker", Version: 2, Status: "starting"},
+		{Name: "web-1", Processname: "web", Version: 2, Status: "started"},
+	})
+
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"process": "web", "version": 2, "ready_units": 1, "total_units": 1},
+		map[string]interface{}{"process": "worker", "version": 2, "ready_units": 0, "total_units": 1},
+	}, flattenProcessReadiness(units))
+}
+
+func TestDeployedVersion(t *testing.T) {
+	data, err := bson.Marshal(map[string]interface{}{"image": "registry/tsuru/app-app01:v3"})
+	require.NoError(t, err)
+
+	e := tsuru.Event{EndCustomData: tsuru.EventStartCustomData{Kind: 3, Data: base64.StdEncoding.EncodeToString(data)}}
+	assert.Equal(t, int32(3), deployedVersion(e))
+	assert.Equal(t, int32(0), deployedVersion(tsuru.Event{}))
+}
+
+func testAppUnitsServer(t *testing.T, responses ...func(c echo.Context) error) *tsuruProvider {
+	fakeServer := echo.New()
+
+	calls := 0
+	fakeServer.GET("/1.0/apps/:name", func(c echo.Context) error {
+		response := responses[len(responses)-1]
+		if calls < len(responses) {
+			response = responses[calls]
+		}
+		calls++
+		return response(c)
+	})
+
+	server := httptest.NewServer(fakeServer)
+	t.Cleanup(server.Close)
+
+	cfg := tsuru.NewConfiguration()
+	cfg.BasePath = server.URL
+	return &tsuruProvider{TsuruClient: tsuru.NewAPIClient(cfg)}
+}
+
+func appUnitsResponse(units ...tsuru.Unit) func(c echo.Context) error {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, &tsuru.App{Name: c.Param("name"), Units: units})
+	}
+}
+
+func TestWaitForAppUnitsReadyDeployedVersion(t *testing.T) {
+	provider := testAppUnitsServer(t,
+		appUnitsResponse(tsuru.Unit{Name: "web-old", Processname: "web", Version: 1, Status: "started", Ready: ptr.To(true)}),
+		func(c echo.Context) error {
+			return c.String(http.StatusConflict, "event locked: app app01")
+		},
+		appUnitsResponse(
+			tsuru.Unit{Name: "web-old", Processname: "web", Version: 1, Status: "started", Ready: ptr.To(true)},
+			tsuru.Unit{Name: "web-new", Processname: "web", Version: 2, Status: "started", Ready: ptr.To(true)},
+		),
+	)
+
+	units, diags := waitForAppUnitsReady(context.Background(), provider, "app01", 2, 10*time.Second)
+	require.False(t, diags.HasError(), diags)
+	require.Len(t, units, 1)
+	assert.Equal(t, "web-new", units[0].Name)
+}
+
+func TestWaitForAppUnitsReadyFailingUnit(t *testing.T) {
+	provider := testAppUnitsServer(t,
+		appUnitsResponse(
+			tsuru.Unit{Name: "web-1", Processname: "web", Version: 2, Status: "started", Ready: ptr.To(true)},
+			tsuru.Unit{Name: "web-2", Processname: "web", Version: 2, Status: "starting", Ready: ptr.To(false), Restarts: ptr.To(4)},
+		),
+	)
+
+	start := time.Now()
+	_, diags := waitForAppUnitsReady(context.Background(), provider, "app01", 2, time.Minute)
+	require.True(t, diags.HasError())
+	assert.Less(t, time.Since(start), 10*time.Second)
+	require.Len(t, diags, 2)
+	assert.Equal(t, "unit web-2 of process web is not ready", diags[1].Summary)
+	assert.Equal(t, `last status: "starting", restarts: 4`, diags[1].Detail)
+}
+
+func TestWaitForAppUnitsReadyNoUnits(t *testing.T) {
+	provider := testAppUnitsServer(t, appUnitsResponse())
+
+	units, diags := waitForAppUnitsReady(context.Background(), provider, "app01", 2, 10*time.Second)
+	require.False(t, diags.HasError(), diags)
+	assert.Empty(t, units)
+}
+
 func testAccResourceTsuruAppDeploy_basic(serverURL string) string {
 	return fmt.Sprintf(`
 
@@ -0,0 +1,204 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	tsuru_client "github.com/tsuru/go-tsuruclient/pkg/tsuru"
+)
+
+func resourceTsuruApplicationMetadata() *schema.Resource {
+	return &schema.Resource{
+		Description: "Manage labels and annotations of a tsuru application, metadata not declared by this resource is preserved. " +
+			"It can be used along with `tsuru_app` for the same app as long as the `metadata` block of `tsuru_app` does not declare the same keys, " +
+			"a `tsuru_app` without `metadata` reads all the metadata of the app and needs `metadata` in its `ignore_changes`. " +
+			"Import adopts all labels and annotations of the app",
+		CreateContext: resourceTsuruApplicationMetadataCreate,
+		ReadContext:   resourceTsuruApplicationMetadataRead,
+		UpdateContext: resourceTsuruApplicationMetadataUpdate,
+		DeleteContext: resourceTsuruApplicationMetadataDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(60 * time.Minute),
+			Update: schema.DefaultTimeout(60 * time.Minute),
+			Delete: schema.DefaultTimeout(60 * time.Minute),
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"app": {
+				Type:        schema.TypeString,
+				Description: "Application name",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"labels": {
+				Type:        schema.TypeMap,
+				Description: "Labels managed by this resource",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"annotations": {
+				Type:        schema.TypeMap,
+				Description: "Annotations managed by this resource",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"restart_on_update": {
+				Type:        schema.TypeBool,
+				Description: "Restart app after applying changes",
+				Optional:    true,
+				Default:     true,
+			},
+		},
+	}
+}
+
+func resourceTsuruApplicationMetadataCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	app := d.Get("app").(string)
+
+	metadata := tsuru_client.Metadata{
+		Labels:      metadataItemsFromMap(d.Get("labels")),
+		Annotations: metadataItemsFromMap(d.Get("annotations")),
+	}
+
+	if err := updateAppMetadata(ctx, d, meta, app, metadata); err != nil {
+		return diag.Errorf("unable to set metadata of app %s: %v", app, err)
+	}
+
+	d.SetId(app)
+
+	return resourceTsuruApplicationMetadataRead(ctx, d, meta)
+}
+
+func resourceTsuruApplicationMetadataRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	provider := meta.(*tsuruProvider)
+	name := d.Id()
+
+	app, _, err := provider.TsuruClient.AppApi.AppGet(ctx, name)
+	if err != nil {
+		if isNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.Errorf("unable to read app %s: %v", name, err)
+	}
+
+	// app is only unset while importing, then everything is adopted
+	if d.Get("app").(string) == "" {
+		d.Set("labels", flattenMetadataItems(app.Metadata.Labels))
+		d.Set("annotations", flattenMetadataItems(app.Metadata.Annotations))
+	} else {
+		d.Set("labels", managedMetadataItems(app.Metadata.Labels, d.Get("labels")))
+		d.Set("annotations", managedMetadataItems(app.Metadata.Annotations, d.Get("annotations")))
+	}
+	d.Set("app", name)
+
+	return nil
+}
+
+func resourceTsuruApplicationMetadataUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	app := d.Id()
+
+	oldLabels, newLabels := d.GetChange("labels")
+	oldAnnotations, newAnnotations := d.GetChange("annotations")
+
+	metadata := tsuru_client.Metadata{
+		Labels:      markRemovedMetadataItemAsDeleted(metadataItemsFromMap(oldLabels), metadataItemsFromMap(newLabels)),
+		Annotations: markRemovedMetadataItemAsDeleted(metadataItemsFromMap(oldAnnotations), metadataItemsFromMap(newAnnotations)),
+	}
+
+	if err := updateAppMetadata(ctx, d, meta, app, metadata); err != nil {
+		return diag.Errorf("unable to update metadata of app %s: %v", app, err)
+	}
+
+	return resourceTsuruApplicationMetadataRead(ctx, d, meta)
+}
+
+func resourceTsuruApplicationMetadataDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	app := d.Id()
+
+	metadata := tsuru_client.Metadata{
+		Labels:      markRemovedMetadataItemAsDeleted(metadataItemsFromMap(d.Get("labels")), nil),
+		Annotations: markRemovedMetadataItemAsDeleted(metadataItemsFromMap(d.Get("annotations")), nil),
+	}
+
+	err := updateAppMetadata(ctx, d, meta, app, metadata)
+	if err != nil && !isNotFoundError(err) {
+		return diag.Errorf("unable to remove metadata of app %s: %v", app, err)
+	}
+
+	return nil
+}
+
+func updateAppMetadata(ctx context.Context, d *schema.ResourceData, meta interface{}, app string, metadata tsuru_client.Metadata) error {
+	provider := meta.(*tsuruProvider)
+
+	// tsuru refuses updates without changes
+	if len(metadata.Labels) == 0 && len(metadata.Annotations) == 0 {
+		return nil
+	}
+
+	return tsuruRetry(ctx, provider, d, func() error {
+		resp, err := provider.TsuruClient.AppApi.AppUpdate(ctx, app, tsuru_client.UpdateApp{
+			Metadata:  metadata,
+			NoRestart: !d.Get("restart_on_update").(bool),
+		})
+		if err != nil {
+			return err
+		}
+
+		defer resp.Body.Close()
+		logTsuruStream(resp.Body)
+
+		return nil
+	})
+}
+
+func metadataItemsFromMap(raw interface{}) []tsuru_client.MetadataItem {
+	items := []tsuru_client.MetadataItem{}
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return items
+	}
+
+	for key, value := range m {
+		items = append(items, tsuru_client.MetadataItem{Name: key, Value: value.(string)})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Name < items[j].Name
+	})
+
+	return items
+}
+
+func flattenMetadataItems(items []tsuru_client.MetadataItem) map[string]interface{} {
+	result := map[string]interface{}{}
+	for _, item := range items {
+		result[item.Name] = item.Value
+	}
+	return result
+}
+
+// managedMetadataItems keeps only the items declared by the resource, the
+// remaining are owned by someone else.
+func managedMetadataItems(items []tsuru_client.MetadataItem, managed interface{}) map[string]interface{} {
+	managedKeys, _ := managed.(map[string]interface{})
+
+	result := map[string]interface{}{}
+	for _, item := range items {
+		if _, ok := managedKeys[item.Name]; ok {
+			result[item.Name] = item.Value
+		}
+	}
+
+	return result
+}
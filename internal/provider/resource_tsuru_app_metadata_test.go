@@ -0,0 +1,185 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
+)
+
+func TestAccResourceTsuruAppMetadata(t *testing.T) {
+	var mutex sync.Mutex
+	labels := map[string]string{"owned-by": "another-tool"}
+	annotations := map[string]string{}
+
+	applyItems := func(current map[string]string, items []tsuru.MetadataItem) {
+		for _, item := range items {
+			if item.Delete {
+				delete(current, item.Name)
+				continue
+			}
+			current[item.Name] = item.Value
+		}
+	}
+	toItems := func(current map[string]string) []tsuru.MetadataItem {
+		items := []tsuru.MetadataItem{}
+		for name, value := range current {
+			items = append(items, tsuru.MetadataItem{Name: name, Value: value})
+		}
+		return items
+	}
+
+	fakeServer := echo.New()
+	fakeServer.PUT("/1.0/apps/:name", func(c echo.Context) error {
+		app := &tsuru.UpdateApp{}
+		err := c.Bind(app)
+		require.NoError(t, err)
+		assert.Equal(t, "app01", c.Param("name"))
+		assert.True(t, app.NoRestart)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		applyItems(labels, app.Metadata.Labels)
+		applyItems(annotations, app.Metadata.Annotations)
+		return nil
+	})
+	fakeServer.GET("/1.0/apps/:name", func(c echo.Context) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return c.JSON(http.StatusOK, &tsuru.App{
+			Name: c.Param("name"),
+			Metadata: tsuru.Metadata{
+				Labels:      toItems(labels),
+				Annotations: toItems(annotations),
+			},
+		})
+	})
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	checkServerMetadata := func(expectedLabels, expectedAnnotations map[string]string) resource.TestCheckFunc {
+		return func(s *terraform.State) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			if !assert.Equal(t, expectedLabels, labels) || !assert.Equal(t, expectedAnnotations, annotations) {
+				return fmt.Errorf("unexpected metadata on server, labels: %v, annotations: %v", labels, annotations)
+			}
+			return nil
+		}
+	}
+
+	resourceName := "tsuru_app_metadata.metadata"
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      checkServerMetadata(map[string]string{"owned-by": "another-tool"}, map[string]string{}),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "tsuru_app_metadata" "metadata" {
+	app    = "app01"
+	labels = {
+		"team" = "platform"
+		"tier" = "frontend"
+	}
+	annotations = {
+		"docs" = "https://docs.example.com"
+	}
+	restart_on_update = false
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccResourceExists(resourceName),
+					resource.TestCheckResourceAttr(resourceName, "labels.%", "2"),
+					resource.TestCheckResourceAttr(resourceName, "labels.tier", "frontend"),
+					resource.TestCheckResourceAttr(resourceName, "annotations.docs", "https://docs.example.com"),
+					checkServerMetadata(
+						map[string]string{"owned-by": "another-tool", "team": "platform", "tier": "frontend"},
+						map[string]string{"docs": "https://docs.example.com"},
+					),
+				),
+			},
+			{
+				Config: `
+resource "tsuru_app_metadata" "metadata" {
+	app    = "app01"
+	labels = {
+		"team" = "platform"
+	}
+	restart_on_update = false
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "labels.%", "1"),
+					resource.TestCheckNoResourceAttr(resourceName, "labels.tier"),
+					resource.TestCheckResourceAttr(resourceName, "annotations.%", "0"),
+					checkServerMetadata(
+						map[string]string{"owned-by": "another-tool", "team": "platform"},
+						map[string]string{},
+					),
+				),
+			},
+		},
+	})
+}
+
+func TestManagedMetadataItems(t *testing.T) {
+	items := []tsuru.MetadataItem{
+		{Name: "team", Value: "platform"},
+		{Name: "owned-by", Value: "another-tool"},
+	}
+
+	managed := managedMetadataItems(items, map[string]interface{}{"team": "old-value", "removed": "value"})
+	assert.Equal(t, map[string]interface{}{"team": "platform"}, managed)
+}
+
+func TestResourceTsuruAppMetadataImport(t *testing.T) {
+	fakeServer := echo.New()
+	fakeServer.GET("/1.0/apps/:name", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, &tsuru.App{
+			Name: c.Param("name"),
+			Metadata: tsuru.Metadata{
+				Labels:      []tsuru.MetadataItem{{Name: "team", Value: "platform"}},
+				Annotations: []tsuru.MetadataItem{{Name: "docs", Value: "https://docs.example.com"}},
+			},
+		})
+	})
+	server := httptest.NewServer(fakeServer)
+	defer server.Close()
+
+	cfg := tsuru.NewConfiguration()
+	cfg.BasePath = server.URL
+	provider := &tsuruProvider{TsuruClient: tsuru.NewAPIClient(cfg)}
+
+	d := resourceTsuruApplicationMetadata().Data(nil)
+	d.SetId("app01")
+
+	diags := resourceTsuruApplicationMetadataRead(context.Background(), d, provider)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "app01", d.Get("app"))
+	assert.Equal(t, map[string]interface{}{"team": "platform"}, d.Get("labels"))
+	assert.Equal(t, map[string]interface{}{"docs": "https://docs.example.com"}, d.Get("annotations"))
+
+	// once adopted only the items in state are kept
+	d.Set("labels", map[string]interface{}{})
+	diags = resourceTsuruApplicationMetadataRead(context.Background(), d, provider)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, map[string]interface{}{}, d.Get("labels"))
+}
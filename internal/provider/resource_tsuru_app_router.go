@@ -6,16 +6,50 @@ package provider
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	sdkvalidation "github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/pkg/errors"
 	tsuru_client "github.com/tsuru/go-tsuruclient/pkg/tsuru"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
+const appRouterIngressClassOpt = "ingress-class"
+
+// appRouterRateLimitOpts maps the fields of rate_limit to the router options
+// turned into ingress-nginx annotations by kubernetes-router.
+var appRouterRateLimitOpts = []struct {
+	field string
+	opt   string
+}{
+	{field: "requests_per_second", opt: "limit-rps"},
+	{field: "burst_multiplier", opt: "limit-burst-multiplier"},
+	{field: "connections", opt: "limit-connections"},
+}
+
+// appRouterHealthCheckOpts maps the fields of health_check to the router
+// options turned into load balancer annotations by kubernetes-router, like the
+// healthcheck-path and success-codes annotations of AWS load balancer
+// controller.
+var appRouterHealthCheckOpts = []struct {
+	field string
+	opt   string
+}{
+	{field: "path", opt: "healthcheck-path"},
+	{field: "expected_status", opt: "success-codes"},
+}
+
+var appRouterSuccessCodesRegexp = regexp.MustCompile(`^[1-5][0-9]{2}(-[1-5][0-9]{2})?(,[1-5][0-9]{2}(-[1-5][0-9]{2})?)*$`)
+
 func resourceTsuruApplicationRouter() *schema.Resource {
 	return &schema.Resource{
 		Description:   "Tsuru Application Router",
@@ -50,6 +84,67 @@ func resourceTsuruApplicationRouter() *schema.Resource {
 					Type: schema.TypeString,
 				},
 			},
+			"ingress_class": {
+				Type:         schema.TypeString,
+				Description:  "Ingress class used by the router for this app, maps to the router option `ingress-class`",
+				Optional:     true,
+				ValidateFunc: validateIngressClass,
+			},
+			"rate_limit": {
+				Type:        schema.TypeList,
+				Description: "Rate limiting of the requests to the app, maps to the router options `limit-rps`, `limit-burst-multiplier` and `limit-connections`, supported by routers backed by ingress-nginx",
+				Optional:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"requests_per_second": {
+							Type:         schema.TypeInt,
+							Description:  "Maximum number of requests per second accepted from a client IP",
+							Optional:     true,
+							ValidateFunc: sdkvalidation.IntAtLeast(1),
+							AtLeastOneOf: []string{"rate_limit.0.requests_per_second", "rate_limit.0.burst_multiplier", "rate_limit.0.connections"},
+						},
+						"burst_multiplier": {
+							Type:         schema.TypeInt,
+							Description:  "Multiplier of requests_per_second accepted as burst, ingress-nginx defaults to 5",
+							Optional:     true,
+							ValidateFunc: sdkvalidation.IntAtLeast(1),
+							AtLeastOneOf: []string{"rate_limit.0.requests_per_second", "rate_limit.0.burst_multiplier", "rate_limit.0.connections"},
+						},
+						"connections": {
+							Type:         schema.TypeInt,
+							Description:  "Maximum number of concurrent connections from a client IP",
+							Optional:     true,
+							ValidateFunc: sdkvalidation.IntAtLeast(1),
+							AtLeastOneOf: []string{"rate_limit.0.requests_per_second", "rate_limit.0.burst_multiplier", "rate_limit.0.connections"},
+						},
+					},
+				},
+			},
+			"health_check": {
+				Type:        schema.TypeList,
+				Description: "Health check done by the load balancer of the router on the app, maps to the router options `healthcheck-path` and `success-codes`, supported by routers backed by load balancers with configurable health checks, like AWS load balancer controller",
+				Optional:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"path": {
+							Type:         schema.TypeString,
+							Description:  "Path requested by the health check, like `/healthz`",
+							Optional:     true,
+							ValidateFunc: sdkvalidation.StringMatch(regexp.MustCompile(`^/\S*$`), "must be an absolute path, like /healthz"),
+							AtLeastOneOf: []string{"health_check.0.path", "health_check.0.expected_status"},
+						},
+						"expected_status": {
+							Type:         schema.TypeString,
+							Description:  "HTTP status codes of healthy responses, a code like `200`, a range like `200-299` or a comma separated list of them",
+							Optional:     true,
+							ValidateFunc: sdkvalidation.StringMatch(appRouterSuccessCodesRegexp, "must be a status code, like 200, a range, like 200-299, or a comma separated list of them"),
+							AtLeastOneOf: []string{"health_check.0.path", "health_check.0.expected_status"},
+						},
+					},
+				},
+			},
 		},
 	}
 }
@@ -63,9 +158,9 @@ func resourceTsuruApplicationRouterCreate(ctx context.Context, d *schema.Resourc
 		return diag.Errorf("unable to create router: %v", err)
 	}
 
-	options := map[string]interface{}{}
-	for key, value := range d.Get("options").(map[string]interface{}) {
-		options[key] = value.(string)
+	options, err := appRouterOptionsFromResourceData(d)
+	if err != nil {
+		return diag.FromErr(err)
 	}
 
 	router := tsuru_client.AppRouter{
@@ -73,7 +168,7 @@ func resourceTsuruApplicationRouterCreate(ctx context.Context, d *schema.Resourc
 		Opts: options,
 	}
 
-	err := resource.RetryContext(ctx, d.Timeout(schema.TimeoutCreate), func() *resource.RetryError {
+	err = resource.RetryContext(ctx, d.Timeout(schema.TimeoutCreate), func() *resource.RetryError {
 		_, err := provider.TsuruClient.AppApi.AppRouterAdd(ctx, appName, router)
 		if err != nil {
 			var apiError tsuru_client.GenericOpenAPIError
@@ -81,7 +176,7 @@ func resourceTsuruApplicationRouterCreate(ctx context.Context, d *schema.Resourc
 				if isRetryableError(apiError.Body()) {
 					return resource.RetryableError(err)
 				}
-				return resource.NonRetryableError(errors.Errorf("unable to create router: %v", err))
+				return resource.NonRetryableError(errors.Errorf("unable to create router: %v", appRouterOptionsError(d, name, err)))
 			}
 		}
 		d.SetId(createID([]string{appName, name}))
@@ -92,15 +187,20 @@ func resourceTsuruApplicationRouterCreate(ctx context.Context, d *schema.Resourc
 		return diag.FromErr(err)
 	}
 
-	return resourceTsuruApplicationRouterRead(ctx, d, meta)
+	return resourceTsuruApplicationRouterReadAndCheck(ctx, d, meta)
 }
 
 func resourceTsuruApplicationRouterRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	provider := meta.(*tsuruProvider)
+	_, diags := readAppRouter(ctx, d, meta.(*tsuruProvider))
+	return diags
+}
 
+// readAppRouter reads the router into d and returns it, the router is nil when
+// the app or the router were removed.
+func readAppRouter(ctx context.Context, d *schema.ResourceData, provider *tsuruProvider) (*tsuru_client.AppRouter, diag.Diagnostics) {
 	parts, err := IDtoParts(d.Id(), 2)
 	if err != nil {
-		return diag.FromErr(err)
+		return nil, diag.FromErr(err)
 	}
 	appName := parts[0]
 	name := parts[1]
@@ -110,15 +210,15 @@ func resourceTsuruApplicationRouterRead(ctx context.Context, d *schema.ResourceD
 	// wpjunior: it is a workaround for threating the response 204 of API
 	if resp != nil && resp.StatusCode == http.StatusNoContent {
 		d.SetId("")
-		return nil
+		return nil, nil
 	}
 
 	if err != nil {
 		if isNotFoundError(err) {
 			d.SetId("")
-			return nil
+			return nil, nil
 		}
-		return diag.Errorf("unable to get app %s: %v", appName, err)
+		return nil, diag.Errorf("unable to get app %s: %v", appName, err)
 	}
 
 	d.Set("app", appName)
@@ -128,11 +228,30 @@ func resourceTsuruApplicationRouterRead(ctx context.Context, d *schema.ResourceD
 			continue
 		}
 		d.Set("name", name)
-		d.Set("options", router.Opts)
-		return nil
+		d.Set("options", flattenAppRouterOptions(d, router.Opts))
+		return &router, nil
 	}
 
-	return diag.Errorf("unable to find router %s on app %s", name, appName)
+	return nil, diag.Errorf("unable to find router %s on app %s", name, appName)
+}
+
+// resourceTsuruApplicationRouterReadAndCheck reads the router after a change,
+// routers usually drop options they do not support instead of refusing them,
+// which would be a perpetual diff.
+func resourceTsuruApplicationRouterReadAndCheck(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	name := d.Get("name").(string)
+	typedOptions := appRouterTypedOptions(d)
+
+	router, diags := readAppRouter(ctx, d, meta.(*tsuruProvider))
+	if diags.HasError() || router == nil || len(typedOptions) == 0 {
+		return diags
+	}
+
+	if unsupported := appRouterUnsupportedFields(typedOptions, router.Opts); len(unsupported) > 0 {
+		return diag.Errorf("router %s does not support %s, it dropped the options sent", name, strings.Join(unsupported, " and "))
+	}
+
+	return nil
 }
 
 func resourceTsuruApplicationRouterUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -140,9 +259,9 @@ func resourceTsuruApplicationRouterUpdate(ctx context.Context, d *schema.Resourc
 	appName := d.Get("app").(string)
 	name := d.Get("name").(string)
 
-	options := map[string]interface{}{}
-	for key, value := range d.Get("options").(map[string]interface{}) {
-		options[key] = value.(string)
+	options, err := appRouterOptionsFromResourceData(d)
+	if err != nil {
+		return diag.FromErr(err)
 	}
 
 	router := tsuru_client.AppRouter{
@@ -150,7 +269,7 @@ func resourceTsuruApplicationRouterUpdate(ctx context.Context, d *schema.Resourc
 		Opts: options,
 	}
 
-	err := resource.RetryContext(ctx, d.Timeout(schema.TimeoutUpdate), func() *resource.RetryError {
+	err = resource.RetryContext(ctx, d.Timeout(schema.TimeoutUpdate), func() *resource.RetryError {
 		_, err := provider.TsuruClient.AppApi.AppRouterUpdate(ctx, appName, name, router)
 		if err != nil {
 			var apiError tsuru_client.GenericOpenAPIError
@@ -158,7 +277,7 @@ func resourceTsuruApplicationRouterUpdate(ctx context.Context, d *schema.Resourc
 				if isRetryableError(apiError.Body()) {
 					return resource.RetryableError(err)
 				}
-				return resource.NonRetryableError(err)
+				return resource.NonRetryableError(appRouterOptionsError(d, name, err))
 			}
 		}
 		return nil
@@ -168,7 +287,7 @@ func resourceTsuruApplicationRouterUpdate(ctx context.Context, d *schema.Resourc
 		return diag.FromErr(err)
 	}
 
-	return resourceTsuruApplicationRouterRead(ctx, d, meta)
+	return resourceTsuruApplicationRouterReadAndCheck(ctx, d, meta)
 }
 
 func resourceTsuruApplicationRouterDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -201,3 +320,201 @@ func validRouter(ctx context.Context, provider *tsuruProvider, router string) er
 	}
 	return errors.Errorf("invalid router: %s", router)
 }
+
+func appRouterOptionsFromResourceData(d *schema.ResourceData) (map[string]interface{}, error) {
+	options := map[string]interface{}{}
+	for key, value := range d.Get("options").(map[string]interface{}) {
+		options[key] = value.(string)
+	}
+
+	if ingressClass, ok := d.GetOk("ingress_class"); ok {
+		if _, found := options[appRouterIngressClassOpt]; found {
+			return nil, errors.Errorf("ingress_class conflicts with option %q, use only one of them", appRouterIngressClassOpt)
+		}
+		options[appRouterIngressClassOpt] = ingressClass.(string)
+	}
+
+	if items := d.Get("rate_limit").([]interface{}); len(items) > 0 && items[0] != nil {
+		rateLimit := items[0].(map[string]interface{})
+		for _, item := range appRouterRateLimitOpts {
+			value := rateLimit[item.field].(int)
+			if value == 0 {
+				continue
+			}
+			if _, found := options[item.opt]; found {
+				return nil, errors.Errorf("rate_limit.%s conflicts with option %q, use only one of them", item.field, item.opt)
+			}
+			options[item.opt] = strconv.Itoa(value)
+		}
+	}
+
+	if items := d.Get("health_check").([]interface{}); len(items) > 0 && items[0] != nil {
+		healthCheck := items[0].(map[string]interface{})
+		for _, item := range appRouterHealthCheckOpts {
+			value := healthCheck[item.field].(string)
+			if value == "" {
+				continue
+			}
+			if _, found := options[item.opt]; found {
+				return nil, errors.Errorf("health_check.%s conflicts with option %q, use only one of them", item.field, item.opt)
+			}
+			options[item.opt] = value
+		}
+	}
+
+	return options, nil
+}
+
+// appRouterTypedOptions returns the router options sent for each of the
+// ingress_class, rate_limit and health_check fields in use.
+func appRouterTypedOptions(d *schema.ResourceData) map[string][]string {
+	typedOptions := map[string][]string{}
+
+	if _, ok := d.GetOk("ingress_class"); ok {
+		typedOptions["ingress_class"] = []string{appRouterIngressClassOpt}
+	}
+
+	if items := d.Get("rate_limit").([]interface{}); len(items) > 0 && items[0] != nil {
+		rateLimit := items[0].(map[string]interface{})
+		for _, item := range appRouterRateLimitOpts {
+			if rateLimit[item.field].(int) != 0 {
+				typedOptions["rate_limit"] = append(typedOptions["rate_limit"], item.opt)
+			}
+		}
+	}
+
+	if items := d.Get("health_check").([]interface{}); len(items) > 0 && items[0] != nil {
+		healthCheck := items[0].(map[string]interface{})
+		for _, item := range appRouterHealthCheckOpts {
+			if healthCheck[item.field].(string) != "" {
+				typedOptions["health_check"] = append(typedOptions["health_check"], item.opt)
+			}
+		}
+	}
+
+	return typedOptions
+}
+
+// appRouterUnsupportedFields returns the fields with options missing from
+// the options read from the router, sorted.
+func appRouterUnsupportedFields(typedOptions map[string][]string, opts map[string]interface{}) []string {
+	unsupported := []string{}
+	for field, fieldOpts := range typedOptions {
+		for _, opt := range fieldOpts {
+			if _, found := opts[opt]; !found {
+				unsupported = append(unsupported, field)
+				break
+			}
+		}
+	}
+	sort.Strings(unsupported)
+	return unsupported
+}
+
+// appRouterOptionsError points to rate_limit and health_check when the router
+// refuses the options, only some routers support them.
+func appRouterOptionsError(d *schema.ResourceData, name string, err error) error {
+	blocks := []string{}
+	for _, block := range []string{"rate_limit", "health_check"} {
+		if len(d.Get(block).([]interface{})) > 0 {
+			blocks = append(blocks, block)
+		}
+	}
+
+	if len(blocks) == 0 {
+		return err
+	}
+	return errors.Errorf("router %s refused the options, check whether it supports %s: %v", name, strings.Join(blocks, " and "), err)
+}
+
+func flattenAppRouterOptions(d *schema.ResourceData, opts map[string]interface{}) map[string]interface{} {
+	options := map[string]interface{}{}
+	for key, value := range opts {
+		options[key] = value
+	}
+
+	configuredOptions := d.Get("options").(map[string]interface{})
+	d.Set("rate_limit", flattenAppRouterRateLimit(configuredOptions, options))
+	d.Set("health_check", flattenAppRouterHealthCheck(configuredOptions, options))
+
+	// keep the option in raw options when the user manages it there
+	if _, found := configuredOptions[appRouterIngressClassOpt]; found {
+		return options
+	}
+
+	ingressClass := ""
+	if value, found := options[appRouterIngressClassOpt]; found {
+		ingressClass = fmt.Sprintf("%v", value)
+		delete(options, appRouterIngressClassOpt)
+	}
+	d.Set("ingress_class", ingressClass)
+
+	return options
+}
+
+// flattenAppRouterRateLimit moves the rate limit options to the rate_limit
+// block, unless they are managed in raw options or are not numbers.
+func flattenAppRouterRateLimit(configuredOptions, options map[string]interface{}) []interface{} {
+	for _, item := range appRouterRateLimitOpts {
+		if _, found := configuredOptions[item.opt]; found {
+			return nil
+		}
+	}
+
+	rateLimit := map[string]interface{}{}
+	for _, item := range appRouterRateLimitOpts {
+		value, found := options[item.opt]
+		if !found {
+			continue
+		}
+		number, err := strconv.Atoi(fmt.Sprintf("%v", value))
+		if err != nil {
+			continue
+		}
+		rateLimit[item.field] = number
+		delete(options, item.opt)
+	}
+
+	if len(rateLimit) == 0 {
+		return nil
+	}
+	return []interface{}{rateLimit}
+}
+
+// flattenAppRouterHealthCheck moves the health check options to the
+// health_check block, unless they are managed in raw options.
+func flattenAppRouterHealthCheck(configuredOptions, options map[string]interface{}) []interface{} {
+	for _, item := range appRouterHealthCheckOpts {
+		if _, found := configuredOptions[item.opt]; found {
+			return nil
+		}
+	}
+
+	healthCheck := map[string]interface{}{}
+	for _, item := range appRouterHealthCheckOpts {
+		value, found := options[item.opt]
+		if !found {
+			continue
+		}
+		healthCheck[item.field] = fmt.Sprintf("%v", value)
+		delete(options, item.opt)
+	}
+
+	if len(healthCheck) == 0 {
+		return nil
+	}
+	return []interface{}{healthCheck}
+}
+
+func validateIngressClass(i interface{}, k string) ([]string, []error) {
+	v, ok := i.(string)
+	if !ok {
+		return nil, []error{errors.Errorf("expected type of %s to be string", k)}
+	}
+
+	var errs []error
+	for _, msg := range validation.IsDNS1123Subdomain(v) {
+		errs = append(errs, errors.Errorf("invalid %s %q: %s", k, v, msg))
+	}
+	return nil, errs
+}
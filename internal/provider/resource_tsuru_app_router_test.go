@@ -5,14 +5,18 @@
 package provider
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	echo "github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
 )
 
@@ -93,3 +97,426 @@ func testAccResourceTsuruAppRouter_basic() string {
 	}
 `
 }
+
+func TestAccResourceTsuruAppRouterIngressClass(t *testing.T) {
+	fakeServer := echo.New()
+
+	iterationCount := 0
+
+	fakeServer.GET("/1.3/routers", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, []tsuru.PlanRouter{{Name: "some-router"}})
+	})
+
+	fakeServer.GET("/1.5/apps/:app/routers", func(c echo.Context) error {
+		routers := []tsuru.AppRouter{}
+		if iterationCount == 1 {
+			routers = append(routers, tsuru.AppRouter{
+				Name: "some-router",
+				Opts: map[string]interface{}{
+					"key1":          "value1",
+					"ingress-class": "nginx-internal",
+				},
+			})
+		}
+		return c.JSON(http.StatusOK, routers)
+	})
+
+	fakeServer.POST("/1.5/apps/:app/routers", func(c echo.Context) error {
+		router := tsuru.AppRouter{}
+		c.Bind(&router)
+		assert.Equal(t, "app01", c.Param("app"))
+		assert.Equal(t, "some-router", router.Name)
+		assert.Equal(t, map[string]interface{}{
+			"key1":          "value1",
+			"ingress-class": "nginx-internal",
+		}, router.Opts)
+		iterationCount++
+		return c.JSON(http.StatusOK, map[string]interface{}{"ok": "true"})
+	})
+
+	fakeServer.DELETE("/1.5/apps/:app/routers/:router", func(c echo.Context) error {
+		assert.Equal(t, "app01", c.Param("app"))
+		assert.Equal(t, "some-router", c.Param("router"))
+		return c.NoContent(http.StatusOK)
+	})
+
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	resourceName := "tsuru_app_router.router"
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      nil,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceTsuruAppRouter_ingressClass(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccResourceExists(resourceName),
+					resource.TestCheckResourceAttr(resourceName, "ingress_class", "nginx-internal"),
+					resource.TestCheckResourceAttr(resourceName, "options.%", "1"),
+					resource.TestCheckResourceAttr(resourceName, "options.key1", "value1"),
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceTsuruAppRouter_ingressClass() string {
+	return `
+	resource "tsuru_app_router" "router" {
+		app = "app01"
+		name = "some-router"
+		ingress_class = "nginx-internal"
+		options = {
+			"key1" = "value1"
+		}
+	}
+`
+}
+
+func TestAccResourceTsuruAppRouterRateLimit(t *testing.T) {
+	fakeServer := echo.New()
+
+	opts := map[string]interface{}{}
+	created := false
+
+	fakeServer.GET("/1.3/routers", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, []tsuru.PlanRouter{{Name: "some-router"}})
+	})
+
+	fakeServer.GET("/1.5/apps/:app/routers", func(c echo.Context) error {
+		routers := []tsuru.AppRouter{}
+		if created {
+			routers = append(routers, tsuru.AppRouter{Name: "some-router", Opts: opts})
+		}
+		return c.JSON(http.StatusOK, routers)
+	})
+
+	fakeServer.POST("/1.5/apps/:app/routers", func(c echo.Context) error {
+		router := tsuru.AppRouter{}
+		c.Bind(&router)
+		assert.Equal(t, map[string]interface{}{
+			"key1":              "value1",
+			"limit-rps":         "10",
+			"limit-connections": "20",
+		}, router.Opts)
+		opts = router.Opts
+		created = true
+		return c.JSON(http.StatusOK, map[string]interface{}{"ok": "true"})
+	})
+
+	fakeServer.PUT("/1.5/apps/:app/routers/:router", func(c echo.Context) error {
+		return c.String(http.StatusBadRequest, "unsupported option limit-burst-multiplier")
+	})
+
+	fakeServer.DELETE("/1.5/apps/:app/routers/:router", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	resourceName := "tsuru_app_router.router"
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      nil,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+	resource "tsuru_app_router" "router" {
+		app = "app01"
+		name = "some-router"
+		options = {
+			"key1" = "value1"
+		}
+		rate_limit {
+			requests_per_second = 10
+			connections = 20
+		}
+	}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccResourceExists(resourceName),
+					resource.TestCheckResourceAttr(resourceName, "rate_limit.0.requests_per_second", "10"),
+					resource.TestCheckResourceAttr(resourceName, "rate_limit.0.connections", "20"),
+					resource.TestCheckResourceAttr(resourceName, "options.%", "1"),
+				),
+			},
+			{
+				Config: `
+	resource "tsuru_app_router" "router" {
+		app = "app01"
+		name = "some-router"
+		options = {
+			"key1" = "value1"
+		}
+		rate_limit {
+			requests_per_second = 10
+			burst_multiplier = 3
+			connections = 20
+		}
+	}
+`,
+				ExpectError: regexp.MustCompile("router some-router refused the options, check whether it supports rate_limit"),
+			},
+			{
+				Config: `
+	resource "tsuru_app_router" "router" {
+		app = "app01"
+		name = "some-router"
+		rate_limit {
+			requests_per_second = 0
+		}
+	}
+`,
+				ExpectError: regexp.MustCompile(`expected rate_limit.0.requests_per_second to be at least \(1\)`),
+			},
+		},
+	})
+}
+
+// testAppRouterDroppingServer serves a router that silently drops the given
+// options, like routers ignoring unknown options.
+func testAppRouterDroppingServer(t *testing.T, dropped ...string) *tsuruProvider {
+	opts := map[string]interface{}{}
+
+	// the router read after a change is also used to check the dropped options
+	listCalls := 0
+	t.Cleanup(func() {
+		assert.LessOrEqual(t, listCalls, 1, "routers of the app listed more than once")
+	})
+
+	fakeServer := echo.New()
+	fakeServer.GET("/1.3/routers", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, []tsuru.PlanRouter{{Name: "some-router"}})
+	})
+	fakeServer.GET("/1.5/apps/:app/routers", func(c echo.Context) error {
+		listCalls++
+		return c.JSON(http.StatusOK, []tsuru.AppRouter{{Name: "some-router", Opts: opts}})
+	})
+	fakeServer.POST("/1.5/apps/:app/routers", func(c echo.Context) error {
+		router := tsuru.AppRouter{}
+		require.NoError(t, c.Bind(&router))
+		for key, value := range router.Opts {
+			kept := true
+			for _, opt := range dropped {
+				if key == opt {
+					kept = false
+				}
+			}
+			if kept {
+				opts[key] = value
+			}
+		}
+		return c.JSON(http.StatusOK, map[string]interface{}{"ok": "true"})
+	})
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+	server := httptest.NewServer(fakeServer)
+	t.Cleanup(server.Close)
+
+	cfg := tsuru.NewConfiguration()
+	cfg.BasePath = server.URL
+	return &tsuruProvider{TsuruClient: tsuru.NewAPIClient(cfg)}
+}
+
+func TestResourceTsuruAppRouterUnsupportedOptions(t *testing.T) {
+	provider := testAppRouterDroppingServer(t, "ingress-class", "limit-connections")
+
+	d := schema.TestResourceDataRaw(t, resourceTsuruApplicationRouter().Schema, map[string]interface{}{
+		"app":           "app01",
+		"name":          "some-router",
+		"options":       map[string]interface{}{"key1": "value1"},
+		"ingress_class": "nginx",
+		"rate_limit": []interface{}{map[string]interface{}{
+			"requests_per_second": 10,
+			"connections":         20,
+		}},
+	})
+
+	diags := resourceTsuruApplicationRouterCreate(context.Background(), d, provider)
+	require.True(t, diags.HasError())
+	assert.Equal(t, "router some-router does not support ingress_class and rate_limit, it dropped the options sent", diags[0].Summary)
+	assert.Equal(t, "app01::some-router", d.Id())
+
+	provider = testAppRouterDroppingServer(t)
+	d = schema.TestResourceDataRaw(t, resourceTsuruApplicationRouter().Schema, map[string]interface{}{
+		"app":           "app01",
+		"name":          "some-router",
+		"ingress_class": "nginx",
+	})
+
+	diags = resourceTsuruApplicationRouterCreate(context.Background(), d, provider)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "nginx", d.Get("ingress_class"))
+}
+
+func TestFlattenAppRouterRateLimit(t *testing.T) {
+	options := map[string]interface{}{"key1": "value1", "limit-rps": "10", "limit-connections": "many"}
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"requests_per_second": 10},
+	}, flattenAppRouterRateLimit(map[string]interface{}{}, options))
+	assert.Equal(t, map[string]interface{}{"key1": "value1", "limit-connections": "many"}, options)
+
+	options = map[string]interface{}{"limit-rps": "10"}
+	assert.Nil(t, flattenAppRouterRateLimit(map[string]interface{}{"limit-rps": "10"}, options))
+	assert.Equal(t, map[string]interface{}{"limit-rps": "10"}, options)
+
+	assert.Nil(t, flattenAppRouterRateLimit(map[string]interface{}{}, map[string]interface{}{}))
+}
+
+func TestAccResourceTsuruAppRouterHealthCheck(t *testing.T) {
+	fakeServer := echo.New()
+
+	opts := map[string]interface{}{}
+	created := false
+
+	fakeServer.GET("/1.3/routers", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, []tsuru.PlanRouter{{Name: "alb-router"}})
+	})
+
+	fakeServer.GET("/1.5/apps/:app/routers", func(c echo.Context) error {
+		routers := []tsuru.AppRouter{}
+		if created {
+			routers = append(routers, tsuru.AppRouter{Name: "alb-router", Opts: opts})
+		}
+		return c.JSON(http.StatusOK, routers)
+	})
+
+	fakeServer.POST("/1.5/apps/:app/routers", func(c echo.Context) error {
+		router := tsuru.AppRouter{}
+		c.Bind(&router)
+		assert.Equal(t, map[string]interface{}{
+			"healthcheck-path": "/healthz",
+			"success-codes":    "200-299",
+		}, router.Opts)
+		opts = router.Opts
+		created = true
+		return c.JSON(http.StatusOK, map[string]interface{}{"ok": "true"})
+	})
+
+	fakeServer.PUT("/1.5/apps/:app/routers/:router", func(c echo.Context) error {
+		return c.String(http.StatusBadRequest, "unsupported option success-codes")
+	})
+
+	fakeServer.DELETE("/1.5/apps/:app/routers/:router", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	resourceName := "tsuru_app_router.router"
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      nil,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+	resource "tsuru_app_router" "router" {
+		app = "app01"
+		name = "alb-router"
+		health_check {
+			path = "/healthz"
+			expected_status = "200-299"
+		}
+	}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccResourceExists(resourceName),
+					resource.TestCheckResourceAttr(resourceName, "health_check.0.path", "/healthz"),
+					resource.TestCheckResourceAttr(resourceName, "health_check.0.expected_status", "200-299"),
+					resource.TestCheckResourceAttr(resourceName, "options.%", "0"),
+				),
+			},
+			{
+				Config: `
+	resource "tsuru_app_router" "router" {
+		app = "app01"
+		name = "alb-router"
+		health_check {
+			path = "/healthz"
+			expected_status = "200"
+		}
+	}
+`,
+				ExpectError: regexp.MustCompile("router alb-router refused the options, check whether it supports health_check"),
+			},
+			{
+				Config: `
+	resource "tsuru_app_router" "router" {
+		app = "app01"
+		name = "alb-router"
+		health_check {
+			path = "healthz"
+			expected_status = "2xx"
+		}
+	}
+`,
+				ExpectError: regexp.MustCompile(`must be an absolute path, like /healthz`),
+			},
+		},
+	})
+}
+
+func TestResourceTsuruAppRouterUnsupportedHealthCheck(t *testing.T) {
+	provider := testAppRouterDroppingServer(t, "healthcheck-path", "success-codes")
+
+	d := schema.TestResourceDataRaw(t, resourceTsuruApplicationRouter().Schema, map[string]interface{}{
+		"app":  "app01",
+		"name": "some-router",
+		"health_check": []interface{}{map[string]interface{}{
+			"path":            "/healthz",
+			"expected_status": "200-299",
+		}},
+	})
+
+	diags := resourceTsuruApplicationRouterCreate(context.Background(), d, provider)
+	require.True(t, diags.HasError())
+	assert.Equal(t, "router some-router does not support health_check, it dropped the options sent", diags[0].Summary)
+}
+
+func TestAppRouterHealthCheckValidation(t *testing.T) {
+	healthCheck := resourceTsuruApplicationRouter().Schema["health_check"].Elem.(*schema.Resource).Schema
+
+	for _, status := range []string{"200", "200-299", "200,204", "200,300-399"} {
+		_, errs := healthCheck["expected_status"].ValidateFunc(status, "expected_status")
+		assert.Empty(t, errs, status)
+	}
+	for _, status := range []string{"", "2xx", "200-", "600", "200;204"} {
+		_, errs := healthCheck["expected_status"].ValidateFunc(status, "expected_status")
+		assert.NotEmpty(t, errs, status)
+	}
+
+	_, errs := healthCheck["path"].ValidateFunc("/healthz", "path")
+	assert.Empty(t, errs)
+	_, errs = healthCheck["path"].ValidateFunc("healthz", "path")
+	assert.NotEmpty(t, errs)
+}
+
+func TestFlattenAppRouterHealthCheck(t *testing.T) {
+	options := map[string]interface{}{"key1": "value1", "healthcheck-path": "/healthz"}
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"path": "/healthz"},
+	}, flattenAppRouterHealthCheck(map[string]interface{}{}, options))
+	assert.Equal(t, map[string]interface{}{"key1": "value1"}, options)
+
+	options = map[string]interface{}{"success-codes": "200"}
+	assert.Nil(t, flattenAppRouterHealthCheck(map[string]interface{}{"success-codes": "200"}, options))
+	assert.Equal(t, map[string]interface{}{"success-codes": "200"}, options)
+
+	assert.Nil(t, flattenAppRouterHealthCheck(map[string]interface{}{}, map[string]interface{}{}))
+}
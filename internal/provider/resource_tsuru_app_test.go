@@ -5,15 +5,21 @@
 package provider
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	echo "github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
+	"k8s.io/utils/ptr"
 )
 
 func TestAccResourceTsuruApp(t *testing.T) {
@@ -70,7 +76,12 @@ func TestAccResourceTsuruApp(t *testing.T) {
 				},
 				Deploys: 2,
 				Units: []tsuru.Unit{
-					{Processname: "web"},
+					{Processname: "web", Status: "started"},
+					{Processname: "web", Status: "starting"},
+				},
+				ServiceInstanceBinds: []tsuru.AppServiceInstanceBinds{
+					{Service: "redis", Instance: "cache", Plan: "small"},
+					{Service: "mysql", Instance: "db"},
 				},
 				InternalAddresses: []tsuru.AppInternalAddresses{
 					{
@@ -246,6 +257,15 @@ func TestAccResourceTsuruApp(t *testing.T) {
 					resource.TestCheckResourceAttr(resourceName, "tags.1", "tagB"),
 					resource.TestCheckResourceAttr(resourceName, "router.0.name", "default-router"),
 					resource.TestCheckResourceAttr(resourceName, "internal_address.0.domain", "app01.namespace.svc.cluster.local"),
+					resource.TestCheckResourceAttr(resourceName, "status", "starting"),
+					resource.TestCheckResourceAttr(resourceName, "units_summary.0.process", "web"),
+					resource.TestCheckResourceAttr(resourceName, "units_summary.0.ready_units", "1"),
+					resource.TestCheckResourceAttr(resourceName, "units_summary.0.total_units", "2"),
+					resource.TestCheckResourceAttr(resourceName, "service_instances.#", "2"),
+					resource.TestCheckResourceAttr(resourceName, "service_instances.0.id", "mysql::db::app01"),
+					resource.TestCheckResourceAttr(resourceName, "service_instances.1.service_name", "redis"),
+					resource.TestCheckResourceAttr(resourceName, "service_instances.1.service_instance", "cache"),
+					resource.TestCheckResourceAttr(resourceName, "service_instances.1.plan", "small"),
 				),
 			},
 			{
@@ -358,3 +378,601 @@ func testAccResourceTsuruApp_metadataAfterUpdate() string {
 	}
 `
 }
+
+func TestAccResourceTsuruAppNormalizedTags(t *testing.T) {
+	fakeServer := echo.New()
+
+	created := false
+
+	fakeServer.GET("/1.0/platforms", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, []tsuru.Platform{{Name: "python"}})
+	})
+
+	fakeServer.GET("/1.0/pools", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, []tsuru.Pool{{Name: "prod"}})
+	})
+
+	fakeServer.GET("/1.0/plans", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, []tsuru.Plan{{Name: "c2m4"}})
+	})
+
+	fakeServer.POST("/1.0/apps", func(c echo.Context) error {
+		app := tsuru.InputApp{}
+		c.Bind(&app)
+		assert.Equal(t, []string{" Team-Payments ", "Tier-1", "Tier-1"}, app.Tags)
+		created = true
+		return c.JSON(http.StatusOK, tsuru.AppCreateResponse{Status: "created"})
+	})
+
+	fakeServer.GET("/1.0/apps/:name", func(c echo.Context) error {
+		if !created {
+			return c.JSON(http.StatusNotFound, nil)
+		}
+		return c.JSON(http.StatusOK, &tsuru.App{
+			Name:      c.Param("name"),
+			TeamOwner: "my-team",
+			Platform:  "python",
+			Plan:      tsuru.Plan{Name: "c2m4"},
+			Pool:      "prod",
+			Tags:      []string{"Team-Payments", "Tier-1"},
+		})
+	})
+
+	fakeServer.DELETE("/1.0/apps/:name", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	resourceName := "tsuru_app.app"
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      nil,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+	resource "tsuru_app" "app" {
+		name = "app01"
+		platform = "python"
+		plan = "c2m4"
+		team_owner = "my-team"
+		pool = "prod"
+		tags = [" Team-Payments ", "Tier-1", "Tier-1"]
+	}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccResourceExists(resourceName),
+					resource.TestCheckResourceAttr(resourceName, "tags.#", "3"),
+					resource.TestCheckResourceAttr(resourceName, "tags.0", " Team-Payments "),
+					resource.TestCheckResourceAttr(resourceName, "tags.1", "Tier-1"),
+					resource.TestCheckResourceAttr(resourceName, "tags.2", "Tier-1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceTsuruAppWaitForHealthyOnPlanChange(t *testing.T) {
+	fakeServer := echo.New()
+
+	plan := "c2m4"
+
+	fakeServer.GET("/1.0/platforms", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, []tsuru.Platform{{Name: "python"}})
+	})
+
+	fakeServer.GET("/1.0/pools", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, []tsuru.Pool{{Name: "prod"}})
+	})
+
+	fakeServer.GET("/1.0/plans", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, []tsuru.Plan{{Name: "c2m4"}, {Name: "c4m8"}})
+	})
+
+	fakeServer.POST("/1.0/apps", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, tsuru.AppCreateResponse{Status: "created"})
+	})
+
+	fakeServer.PUT("/1.0/apps/:name", func(c echo.Context) error {
+		app := tsuru.UpdateApp{}
+		c.Bind(&app)
+		assert.Equal(t, "c4m8", app.Plan)
+		plan = app.Plan
+		return c.JSON(http.StatusOK, nil)
+	})
+
+	fakeServer.GET("/1.0/apps/:name", func(c echo.Context) error {
+		units := []tsuru.Unit{
+			{Name: "app01-web-1", Processname: "web", Version: 1, Status: "started", Ready: ptr.To(true)},
+		}
+		if plan == "c4m8" {
+			units = []tsuru.Unit{
+				{Name: "app01-web-2", Processname: "web", Version: 1, Status: "starting", Ready: ptr.To(false), Restarts: ptr.To(3)},
+			}
+		}
+
+		return c.JSON(http.StatusOK, &tsuru.App{
+			Name:      c.Param("name"),
+			TeamOwner: "my-team",
+			Platform:  "python",
+			Plan:      tsuru.Plan{Name: plan},
+			Pool:      "prod",
+			Units:     units,
+		})
+	})
+
+	fakeServer.DELETE("/1.0/apps/:name", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	config := func(plan string) string {
+		return fmt.Sprintf(`
+	resource "tsuru_app" "app" {
+		name = "app01"
+		platform = "python"
+		plan = "%s"
+		team_owner = "my-team"
+		pool = "prod"
+		wait_for_healthy = true
+
+		timeouts {
+			update = "3s"
+		}
+	}
+`, plan)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      nil,
+		Steps: []resource.TestStep{
+			{
+				Config: config("c2m4"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccResourceExists("tsuru_app.app"),
+					resource.TestCheckResourceAttr("tsuru_app.app", "wait_for_healthy", "true"),
+				),
+			},
+			{
+				Config:      config("c4m8"),
+				ExpectError: regexp.MustCompile(`unit app01-web-2 of process web is not ready`),
+			},
+		},
+	})
+}
+
+func TestAppStatus(t *testing.T) {
+	assert.Equal(t, "stopped", appStatus(tsuru.App{}))
+	assert.Equal(t, "stopped", appStatus(tsuru.App{Units: []tsuru.Unit{{Status: "stopped"}, {Status: "asleep"}}}))
+	assert.Equal(t, "started", appStatus(tsuru.App{Units: []tsuru.Unit{{Status: "started"}, {Status: "started"}}}))
+	assert.Equal(t, "starting", appStatus(tsuru.App{Units: []tsuru.Unit{{Status: "started"}, {Status: "starting"}}}))
+	assert.Equal(t, "error", appStatus(tsuru.App{Units: []tsuru.Unit{{Status: "started"}, {Status: "error"}}}))
+	assert.Equal(t, "error", appStatus(tsuru.App{Error: "unable to provision", Units: []tsuru.Unit{{Status: "started"}}}))
+}
+
+func TestFlattenUnitsSummary(t *testing.T) {
+	units := []tsuru.Unit{
+		{Processname: "worker", Status: "started"},
+		{Processname: "web", Status: "started", Ready: ptr.To(false)},
+		{Processname: "web", Status: "started"},
+		{Processname: "web", Status: "error"},
+	}
+
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"process": "web", "ready_units": 1, "total_units": 3},
+		map[string]interface{}{"process": "worker", "ready_units": 1, "total_units": 1},
+	}, flattenUnitsSummary(units))
+	assert.Equal(t, []interface{}{}, flattenUnitsSummary(nil))
+}
+
+func TestAccResourceTsuruAppPlatformVersionRollback(t *testing.T) {
+	fakeServer := echo.New()
+
+	platform := "python"
+
+	fakeServer.GET("/1.0/platforms", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, []tsuru.Platform{{Name: "python"}})
+	})
+
+	fakeServer.GET("/1.6/platforms/:platform", func(c echo.Context) error {
+		assert.Equal(t, "python", c.Param("platform"))
+		return c.JSON(http.StatusOK, tsuru.PlatformInfo{
+			Platform: tsuru.Platform{Name: "python"},
+			Images:   []string{"registry.io/tsuru/python:v1", "registry.io/tsuru/python:v2", "registry.io/tsuru/python:v3"},
+		})
+	})
+
+	fakeServer.GET("/1.0/pools", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, []tsuru.Pool{{Name: "prod"}})
+	})
+
+	fakeServer.GET("/1.0/plans", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, []tsuru.Plan{{Name: "c2m4"}})
+	})
+
+	fakeServer.POST("/1.0/apps", func(c echo.Context) error {
+		app := tsuru.InputApp{}
+		c.Bind(&app)
+		assert.Equal(t, "python", app.Platform)
+		return c.JSON(http.StatusOK, tsuru.AppCreateResponse{Status: "created"})
+	})
+
+	fakeServer.PUT("/1.0/apps/:name", func(c echo.Context) error {
+		app := tsuru.UpdateApp{}
+		c.Bind(&app)
+		assert.Equal(t, "python:v2", app.Platform)
+		platform = app.Platform
+		return c.JSON(http.StatusOK, nil)
+	})
+
+	fakeServer.GET("/1.0/apps/:name", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, &tsuru.App{
+			Name:      c.Param("name"),
+			TeamOwner: "my-team",
+			Platform:  platform,
+			Plan:      tsuru.Plan{Name: "c2m4"},
+			Pool:      "prod",
+		})
+	})
+
+	fakeServer.DELETE("/1.0/apps/:name", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	config := func(version string) string {
+		return fmt.Sprintf(`
+	resource "tsuru_app" "app" {
+		name = "app01"
+		platform = "python"
+		platform_version = %q
+		plan = "c2m4"
+		team_owner = "my-team"
+		pool = "prod"
+	}
+`, version)
+	}
+
+	resourceName := "tsuru_app.app"
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      nil,
+		Steps: []resource.TestStep{
+			{
+				Config: config("latest"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccResourceExists(resourceName),
+					resource.TestCheckResourceAttr(resourceName, "platform", "python"),
+					resource.TestCheckResourceAttr(resourceName, "platform_version", "latest"),
+				),
+			},
+			{
+				Config:      config("v0"),
+				ExpectError: regexp.MustCompile(`invalid platform version: v0 available versions of platform python are \[v1,v2,v3\]`),
+			},
+			{
+				Config: config("v2"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "platform", "python"),
+					resource.TestCheckResourceAttr(resourceName, "platform_version", "v2"),
+				),
+			},
+		},
+	})
+}
+
+func TestSplitPlatformVersion(t *testing.T) {
+	for platform, expected := range map[string][2]string{
+		"python":     {"python", "latest"},
+		"python:":    {"python", "latest"},
+		"python:v2":  {"python", "v2"},
+		"python:3.1": {"python", "3.1"},
+	} {
+		name, version := splitPlatformVersion(platform)
+		assert.Equal(t, expected, [2]string{name, version}, platform)
+	}
+}
+
+func TestAppPlatform(t *testing.T) {
+	fakeServer := echo.New()
+	fakeServer.GET("/1.0/platforms", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, []tsuru.Platform{{Name: "python"}})
+	})
+	fakeServer.GET("/1.6/platforms/:platform", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, tsuru.PlatformInfo{Images: []string{"tsuru/python:v1", "tsuru/python:v2"}})
+	})
+	server := httptest.NewServer(fakeServer)
+	defer server.Close()
+
+	cfg := tsuru.NewConfiguration()
+	cfg.BasePath = server.URL
+	provider := &tsuruProvider{TsuruClient: tsuru.NewAPIClient(cfg)}
+
+	for _, tt := range []struct {
+		raw      map[string]interface{}
+		expected string
+		err      string
+	}{
+		{raw: map[string]interface{}{"platform": "python"}, expected: "python"},
+		{raw: map[string]interface{}{"platform": "python:v1"}, expected: "python:v1"},
+		{raw: map[string]interface{}{"platform": "python", "platform_version": "v1"}, expected: "python:v1"},
+		{raw: map[string]interface{}{"platform": "python", "platform_version": "latest"}, expected: "python"},
+		{raw: map[string]interface{}{"platform": "python:v1", "platform_version": "v2"}, err: "platform python:v1 already has a version, set either a versioned platform or platform_version"},
+		{raw: map[string]interface{}{"platform": "python", "platform_version": "v3"}, err: "invalid platform version: v3 available versions of platform python are [v1,v2]"},
+	} {
+		d := schema.TestResourceDataRaw(t, resourceTsuruApplication().Schema, tt.raw)
+		platform, err := appPlatform(context.Background(), provider, d)
+		if tt.err != "" {
+			assert.EqualError(t, err, tt.err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, platform)
+	}
+}
+
+func TestResourceTsuruAppUpdateNoRestart(t *testing.T) {
+	for _, noRestart := range []bool{false, true} {
+		var updated *tsuru.UpdateApp
+
+		fakeServer := echo.New()
+		fakeServer.GET("/1.0/platforms", func(c echo.Context) error {
+			return c.JSON(http.StatusOK, []tsuru.Platform{{Name: "python"}})
+		})
+		fakeServer.GET("/1.0/pools", func(c echo.Context) error {
+			return c.JSON(http.StatusOK, []tsuru.Pool{{Name: "prod"}})
+		})
+		fakeServer.GET("/1.0/plans", func(c echo.Context) error {
+			return c.JSON(http.StatusOK, []tsuru.Plan{{Name: "c2m4"}})
+		})
+		fakeServer.PUT("/1.0/apps/:name", func(c echo.Context) error {
+			updated = &tsuru.UpdateApp{}
+			require.NoError(t, c.Bind(updated))
+			return c.JSON(http.StatusOK, nil)
+		})
+		fakeServer.GET("/1.0/apps/:name", func(c echo.Context) error {
+			return c.JSON(http.StatusOK, &tsuru.App{
+				Name:      c.Param("name"),
+				TeamOwner: "my-team",
+				Platform:  "python",
+				Plan:      tsuru.Plan{Name: "c2m4"},
+				Pool:      "prod",
+			})
+		})
+		fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+			t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+		}
+		server := httptest.NewServer(fakeServer)
+
+		cfg := tsuru.NewConfiguration()
+		cfg.BasePath = server.URL
+		provider := &tsuruProvider{TsuruClient: tsuru.NewAPIClient(cfg)}
+
+		d := schema.TestResourceDataRaw(t, resourceTsuruApplication().Schema, map[string]interface{}{
+			"name":       "app01",
+			"platform":   "python",
+			"plan":       "c2m4",
+			"team_owner": "my-team",
+			"pool":       "prod",
+			"no_restart": noRestart,
+		})
+		d.SetId("app01")
+
+		diags := resourceTsuruApplicationUpdate(context.Background(), d, provider)
+		server.Close()
+		require.False(t, diags.HasError(), "%v", diags)
+		require.NotNil(t, updated)
+		assert.Equal(t, "c2m4", updated.Plan)
+		assert.Equal(t, noRestart, updated.NoRestart)
+	}
+}
+
+func TestResourceTsuruAppTrackingAnnotations(t *testing.T) {
+	var created *tsuru.InputApp
+	var updated *tsuru.UpdateApp
+	annotations := []tsuru.MetadataItem{}
+
+	fakeServer := echo.New()
+	fakeServer.GET("/1.0/platforms", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, []tsuru.Platform{{Name: "python"}})
+	})
+	fakeServer.GET("/1.0/pools", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, []tsuru.Pool{{Name: "prod"}})
+	})
+	fakeServer.GET("/1.0/plans", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, []tsuru.Plan{{Name: "c2m4"}})
+	})
+	fakeServer.POST("/1.0/apps", func(c echo.Context) error {
+		created = &tsuru.InputApp{}
+		require.NoError(t, c.Bind(created))
+		annotations = created.Metadata.Annotations
+		return c.JSON(http.StatusOK, tsuru.AppCreateResponse{Status: "created"})
+	})
+	fakeServer.PUT("/1.0/apps/:name", func(c echo.Context) error {
+		updated = &tsuru.UpdateApp{}
+		require.NoError(t, c.Bind(updated))
+		return c.JSON(http.StatusOK, nil)
+	})
+	fakeServer.GET("/1.0/apps/:name", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, &tsuru.App{
+			Name:      c.Param("name"),
+			TeamOwner: "my-team",
+			Platform:  "python",
+			Plan:      tsuru.Plan{Name: "c2m4"},
+			Pool:      "prod",
+			Metadata:  tsuru.Metadata{Annotations: annotations},
+		})
+	})
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+	server := httptest.NewServer(fakeServer)
+	defer server.Close()
+
+	cfg := tsuru.NewConfiguration()
+	cfg.BasePath = server.URL
+	provider := &tsuruProvider{TsuruClient: tsuru.NewAPIClient(cfg)}
+
+	d := schema.TestResourceDataRaw(t, resourceTsuruApplication().Schema, map[string]interface{}{
+		"name":       "app01",
+		"platform":   "python",
+		"plan":       "c2m4",
+		"team_owner": "my-team",
+		"pool":       "prod",
+		"metadata": []interface{}{map[string]interface{}{
+			"annotations": map[string]interface{}{"owner": "payments"},
+		}},
+		"tracking_annotations": map[string]interface{}{
+			"managed-by": "terraform",
+			"workspace":  "prod",
+		},
+	})
+
+	diags := resourceTsuruApplicationCreate(context.Background(), d, provider)
+	require.False(t, diags.HasError(), "%v", diags)
+	require.NotNil(t, created)
+	assert.Equal(t, []tsuru.MetadataItem{
+		{Name: "owner", Value: "payments"},
+		{Name: "managed-by", Value: "terraform"},
+		{Name: "workspace", Value: "prod"},
+	}, created.Metadata.Annotations)
+	assert.Equal(t, map[string]interface{}{"owner": "payments"}, d.Get("metadata.0.annotations"))
+	assert.Equal(t, map[string]interface{}{"managed-by": "terraform", "workspace": "prod"}, d.Get("tracking_annotations"))
+
+	// removed outside of terraform
+	annotations = []tsuru.MetadataItem{{Name: "owner", Value: "payments"}, {Name: "workspace", Value: "prod"}}
+	diags = resourceTsuruApplicationRead(context.Background(), d, provider)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, map[string]interface{}{"owner": "payments"}, d.Get("metadata.0.annotations"))
+	assert.Equal(t, map[string]interface{}{"workspace": "prod"}, d.Get("tracking_annotations"))
+
+	d = schema.TestResourceDataRaw(t, resourceTsuruApplication().Schema, map[string]interface{}{
+		"name":       "app01",
+		"platform":   "python",
+		"plan":       "c2m4",
+		"team_owner": "my-team",
+		"pool":       "prod",
+		"tracking_annotations": map[string]interface{}{
+			"managed-by": "terraform",
+		},
+	})
+	d.SetId("app01")
+
+	diags = resourceTsuruApplicationUpdate(context.Background(), d, provider)
+	require.False(t, diags.HasError(), "%v", diags)
+	require.NotNil(t, updated)
+	assert.Equal(t, []tsuru.MetadataItem{{Name: "managed-by", Value: "terraform"}}, updated.Metadata.Annotations)
+}
+
+func TestMergeTrackingAnnotations(t *testing.T) {
+	metadata := tsuru.Metadata{
+		Labels:      []tsuru.MetadataItem{{Name: "tier", Value: "1"}},
+		Annotations: []tsuru.MetadataItem{{Name: "owner", Value: "payments"}},
+	}
+
+	merged, err := mergeTrackingAnnotations(metadata, nil)
+	require.NoError(t, err)
+	assert.Equal(t, metadata, merged)
+
+	merged, err = mergeTrackingAnnotations(metadata, map[string]interface{}{"module": "apps", "managed-by": "terraform"})
+	require.NoError(t, err)
+	assert.Equal(t, tsuru.Metadata{
+		Labels: []tsuru.MetadataItem{{Name: "tier", Value: "1"}},
+		Annotations: []tsuru.MetadataItem{
+			{Name: "owner", Value: "payments"},
+			{Name: "managed-by", Value: "terraform"},
+			{Name: "module", Value: "apps"},
+		},
+	}, merged)
+	assert.Len(t, metadata.Annotations, 1)
+
+	_, err = mergeTrackingAnnotations(metadata, map[string]interface{}{"owner": "terraform"})
+	assert.EqualError(t, err, `annotation "owner" is set in both metadata and tracking_annotations`)
+}
+
+func TestDeclaredMetadata(t *testing.T) {
+	metadata := tsuru.Metadata{
+		Labels: []tsuru.MetadataItem{
+			{Name: "team", Value: "platform"},
+			{Name: "owned-by", Value: "tsuru_app_metadata"},
+		},
+		Annotations: []tsuru.MetadataItem{{Name: "docs", Value: "https://docs.example.com"}},
+	}
+
+	assert.Equal(t, metadata, declaredMetadata(metadata, []interface{}{}))
+	assert.Equal(t, tsuru.Metadata{
+		Labels:      []tsuru.MetadataItem{{Name: "team", Value: "platform"}},
+		Annotations: []tsuru.MetadataItem{},
+	}, declaredMetadata(metadata, []interface{}{map[string]interface{}{
+		"labels":      map[string]interface{}{"team": "old-value", "removed": "value"},
+		"annotations": map[string]interface{}{},
+	}}))
+}
+
+func TestResourceTsuruAppReadMetadataOwnership(t *testing.T) {
+	fakeServer := echo.New()
+	fakeServer.GET("/1.0/apps/:name", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, &tsuru.App{
+			Name:      c.Param("name"),
+			TeamOwner: "my-team",
+			Platform:  "python",
+			Plan:      tsuru.Plan{Name: "c2m4"},
+			Pool:      "prod",
+			Metadata: tsuru.Metadata{
+				Labels:      []tsuru.MetadataItem{{Name: "team", Value: "platform"}, {Name: "foreign", Value: "value"}},
+				Annotations: []tsuru.MetadataItem{{Name: "docs", Value: "https://docs.example.com"}},
+			},
+		})
+	})
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+	server := httptest.NewServer(fakeServer)
+	defer server.Close()
+
+	cfg := tsuru.NewConfiguration()
+	cfg.BasePath = server.URL
+	provider := &tsuruProvider{TsuruClient: tsuru.NewAPIClient(cfg)}
+
+	// like after an import, nothing is declared and all the metadata is adopted
+	d := schema.TestResourceDataRaw(t, resourceTsuruApplication().Schema, map[string]interface{}{
+		"name": "app01",
+	})
+	d.SetId("app01")
+
+	diags := resourceTsuruApplicationRead(context.Background(), d, provider)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, map[string]interface{}{"team": "platform", "foreign": "value"}, d.Get("metadata.0.labels"))
+	assert.Equal(t, map[string]interface{}{"docs": "https://docs.example.com"}, d.Get("metadata.0.annotations"))
+
+	d = schema.TestResourceDataRaw(t, resourceTsuruApplication().Schema, map[string]interface{}{
+		"name": "app01",
+		"metadata": []interface{}{map[string]interface{}{
+			"labels": map[string]interface{}{"team": "platform"},
+		}},
+	})
+	d.SetId("app01")
+
+	diags = resourceTsuruApplicationRead(context.Background(), d, provider)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, map[string]interface{}{"team": "platform"}, d.Get("metadata.0.labels"))
+	assert.Equal(t, map[string]interface{}{}, d.Get("metadata.0.annotations"))
+}
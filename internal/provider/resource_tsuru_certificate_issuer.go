@@ -6,11 +6,22 @@ package provider
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
 	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
 )
 
@@ -19,6 +30,7 @@ func resourceTsuruCertificateIssuer() *schema.Resource {
 		Description:   "Set a issuer to generate certificates to a tsuru application",
 		CreateContext: resourceTsuruCertificateIssuerSet,
 		ReadContext:   resourceTsuruCertificateIssuerRead,
+		UpdateContext: resourceTsuruCertificateIssuerRead,
 		DeleteContext: resourceTsuruCertificateIssuerUnset,
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(60 * time.Minute),
@@ -45,11 +57,46 @@ func resourceTsuruCertificateIssuer() *schema.Resource {
 
 			"issuer": {
 				Type:        schema.TypeString,
-				Description: "Certificate Issuer",
+				Description: "Certificate Issuer, an issuer set for the cname outside of terraform is detected and the declared one is restored",
 				Required:    true,
 				ForceNew:    true,
 			},
 
+			"wait_for_ready": {
+				Type:        schema.TypeBool,
+				Description: "Wait for the certificate to be ready when the issuer is set",
+				Optional:    true,
+				Default:     false,
+			},
+
+			"expect_http01": {
+				Type:        schema.TypeBool,
+				Description: "Whether the issuer solves HTTP-01 challenges, which only succeed after the cname points to tsuru, so the wait for readiness is skipped while the cname does not resolve. Set to false for DNS-01 issuers to wait for the certificate before the DNS cutover",
+				Optional:    true,
+				Default:     true,
+			},
+
+			"routers": {
+				Type:        schema.TypeList,
+				Description: "Only reconcile the certificate on these routers, ignoring routers managed outside of terraform. All routers of the app are considered when unset",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+
+			"remove_certificate_on_unset": {
+				Type:        schema.TypeBool,
+				Description: "Also remove the certificate of the cname from the routers when the issuer is unset, tsuru may keep the generated certificate attached otherwise",
+				Optional:    true,
+				Default:     false,
+			},
+
+			"wait_for_cleanup": {
+				Type:        schema.TypeBool,
+				Description: "Wait, within the delete timeout, for the routers to stop reporting the issuer for the cname when it is unset, and the certificate as well with `remove_certificate_on_unset`, as the cleanup may happen asynchronously",
+				Optional:    true,
+				Default:     false,
+			},
+
 			"router": {
 				Type:        schema.TypeList,
 				Description: "Routers that are using the certificate",
@@ -69,6 +116,25 @@ func resourceTsuruCertificateIssuer() *schema.Resource {
 				Description: "If the certificate is ready",
 				Computed:    true,
 			},
+
+			"effective_issuer": {
+				Type:        schema.TypeString,
+				Description: "Issuer used by the routers for the cname, empty when no issuer applies",
+				Computed:    true,
+			},
+
+			"issuer_source": {
+				Type:        schema.TypeString,
+				Description: "Where the effective issuer comes from: `app` when it is the issuer set on the app, `router` when it is inherited from defaults configured outside of the app (pool or cluster), empty when no issuer applies",
+				Computed:    true,
+			},
+
+			"covered_cnames": {
+				Type:        schema.TypeList,
+				Description: "DNS names covered by the generated certificates (SANs), filled after the certificate is ready",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
 		},
 	}
 }
@@ -91,7 +157,60 @@ func resourceTsuruCertificateIssuerSet(ctx context.Context, d *schema.ResourceDa
 
 	d.SetId(app + "::" + cname + "::" + issuer)
 
-	return resourceTsuruCertificateIssuerRead(ctx, d, meta)
+	var diags diag.Diagnostics
+	if d.Get("wait_for_ready").(bool) {
+		diags = waitForCertificateIssuerReady(ctx, d, provider, app, cname, issuer)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	return append(diags, resourceTsuruCertificateIssuerRead(ctx, d, meta)...)
+}
+
+var certificateIssuerLookupHost = net.DefaultResolver.LookupHost
+
+func waitForCertificateIssuerReady(ctx context.Context, d *schema.ResourceData, provider *tsuruProvider, app, cname, issuer string) diag.Diagnostics {
+	if d.Get("expect_http01").(bool) {
+		if _, err := certificateIssuerLookupHost(ctx, cname); err != nil {
+			return diag.Diagnostics{{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("not waiting for the certificate of cname %s to be ready", cname),
+				Detail:   fmt.Sprintf("cname %s does not resolve yet (%v), HTTP-01 challenges only succeed after the DNS cutover. Set expect_http01 = false when the issuer solves DNS-01 challenges.", cname, err),
+			}}
+		}
+	}
+
+	err := waitForCertificateIssued(ctx, provider, app, cname, issuer, d.Get("routers").([]interface{}), d.Timeout(schema.TimeoutCreate))
+	if err != nil {
+		return diag.Errorf("unable to wait for the certificate of cname %s to be ready: %v", cname, err)
+	}
+
+	return nil
+}
+
+func waitForCertificateIssued(ctx context.Context, provider *tsuruProvider, app, cname, issuer string, routers []interface{}, timeout time.Duration) error {
+	return resource.RetryContext(ctx, timeout, func() *resource.RetryError {
+		appCertificates, _, err := provider.TsuruClient.AppApi.AppGetCertificates(ctx, app)
+		if err != nil {
+			var apiError tsuru.GenericOpenAPIError
+			if errors.As(err, &apiError) {
+				if isRetryableError(apiError.Body()) {
+					return resource.RetryableError(err)
+				}
+			}
+			return resource.NonRetryableError(err)
+		}
+
+		appCertificates = filterCertificateRouters(appCertificates, routers)
+		_, certificates := certificatesByIssuer(appCertificates, cname, issuer)
+		if len(certificates) == 0 {
+			log.Printf("[DEBUG] waiting for certificate of cname %s on app %s to be ready", cname, app)
+			return resource.RetryableError(&certificateNotReadyError{cname: cname})
+		}
+
+		return nil
+	})
 }
 
 func resourceTsuruCertificateIssuerUnset(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -102,6 +221,7 @@ func resourceTsuruCertificateIssuerUnset(ctx context.Context, d *schema.Resource
 	}
 	app := parts[0]
 	cname := parts[1]
+	issuer := parts[2]
 
 	_, err = provider.TsuruClient.AppApi.AppUnsetCertIssuer(context.Background(), app, cname)
 
@@ -109,9 +229,123 @@ func resourceTsuruCertificateIssuerUnset(ctx context.Context, d *schema.Resource
 		return diag.Errorf("unable to unset certificate issuer: %v", err)
 	}
 
+	removeCertificate := d.Get("remove_certificate_on_unset").(bool)
+	if removeCertificate {
+		err = tsuruRetry(ctx, provider, d, func() error {
+			return unsetAppCertificate(ctx, provider, app, cname)
+		})
+		if isCertificateNotFoundError(err) {
+			log.Printf("[DEBUG] cname %s of app %s has no certificate to remove", cname, app)
+			err = nil
+		}
+		if err != nil {
+			return diag.Errorf("unable to remove certificate of cname %s: %v", cname, err)
+		}
+	}
+
+	if d.Get("wait_for_cleanup").(bool) {
+		timeout := d.Timeout(schema.TimeoutDelete)
+		err = waitForCertificateCleanup(ctx, provider, app, cname, issuer, d.Get("routers").([]interface{}), removeCertificate, timeout)
+		var pendingErr *certificateCleanupPendingError
+		if errors.As(err, &pendingErr) {
+			return diag.Diagnostics{{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("cleanup of the certificate of cname %s did not finish within %s", cname, timeout),
+				Detail:   fmt.Sprintf("%s, the issuer is already unset, destroy again to keep waiting", pendingErr),
+			}}
+		}
+		if err != nil {
+			return diag.Errorf("unable to wait for the cleanup of the certificate of cname %s: %v", cname, err)
+		}
+	}
+
 	return resourceTsuruCertificateIssuerRead(ctx, d, meta)
 }
 
+// waitForCertificateCleanup waits for the routers to stop reporting the issuer
+// for the cname, and its certificate when removeCertificate is set, since
+// cert-manager removes them asynchronously.
+func waitForCertificateCleanup(ctx context.Context, provider *tsuruProvider, app, cname, issuer string, routers []interface{}, removeCertificate bool, timeout time.Duration) error {
+	return resource.RetryContext(ctx, timeout, func() *resource.RetryError {
+		appCertificates, _, err := provider.TsuruClient.AppApi.AppGetCertificates(ctx, app)
+		if err != nil {
+			var apiError tsuru.GenericOpenAPIError
+			if errors.As(err, &apiError) {
+				if isRetryableError(apiError.Body()) {
+					return resource.RetryableError(err)
+				}
+			}
+			return resource.NonRetryableError(err)
+		}
+
+		appCertificates = filterCertificateRouters(appCertificates, routers)
+		pending := []string{}
+		for routerName, router := range appCertificates.Routers {
+			cnameInRouter, ok := router.Cnames[cname]
+			if !ok {
+				continue
+			}
+			if cnameInRouter.Issuer == issuer || (removeCertificate && cnameInRouter.Certificate != "") {
+				pending = append(pending, routerName)
+			}
+		}
+
+		if len(pending) > 0 {
+			sort.Strings(pending)
+			log.Printf("[DEBUG] waiting for cleanup of certificate of cname %s on app %s, routers: %v", cname, app, pending)
+			return resource.RetryableError(&certificateCleanupPendingError{cname: cname, routers: pending})
+		}
+
+		return nil
+	})
+}
+
+// unsetAppCertificate removes the certificate of the cname from the routers
+// of the app. AppApi.CertificatUnset is not used because it cannot send the
+// cname, which would remove the certificates of every cname of the app.
+func unsetAppCertificate(ctx context.Context, provider *tsuruProvider, app, cname string) error {
+	values := url.Values{}
+	values.Set("cname", cname)
+
+	endpoint := fmt.Sprintf("%s/1.24/apps/%s/certificate?%s", provider.Host, app, values.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	token := provider.Token
+	if token == "" {
+		token = deployToken()
+	}
+	req.Header.Set("Authorization", token)
+
+	resp, err := provider.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		return &rawAPIError{StatusCode: resp.StatusCode, Body: body}
+	}
+
+	return nil
+}
+
+// isCertificateNotFoundError matches the answer of tsuru for cnames without
+// a certificate, the router error is reported as a bad request.
+func isCertificateNotFoundError(err error) bool {
+	if isNotFoundError(err) {
+		return true
+	}
+	var rawError *rawAPIError
+	return errors.As(err, &rawError) && strings.Contains(string(rawError.Body), "Certificate not found")
+}
+
 func resourceTsuruCertificateIssuerRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	provider := meta.(*tsuruProvider)
 	parts, err := IDtoParts(d.Id(), 3)
@@ -122,11 +356,92 @@ func resourceTsuruCertificateIssuerRead(ctx context.Context, d *schema.ResourceD
 	cname := parts[1]
 	issuer := parts[2]
 
-	certificates, _, err := provider.TsuruClient.AppApi.AppGetCertificates(context.Background(), app)
+	certificates, _, err := provider.TsuruClient.AppApi.AppGetCertificates(ctx, app)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
+	certificates = filterCertificateRouters(certificates, d.Get("routers").([]interface{}))
+	usedRouters, usedCertificates := certificatesByIssuer(certificates, cname, issuer)
+	effectiveIssuer, issuerSource := effectiveCertificateIssuer(certificates, cname, issuer)
+
+	coveredCNames := []string{}
+	for _, certificate := range usedCertificates {
+		dnsNames, err := certificateDNSNames(certificate)
+		if err != nil {
+			log.Printf("[WARN] unable to parse certificate of cname %s on app %s: %v", cname, app, err)
+			continue
+		}
+		coveredCNames = append(coveredCNames, dnsNames...)
+	}
+	coveredCNames = uniqueSortedStrings(coveredCNames)
+
+	// the issuer was changed outside of terraform, e.g. with tsuru CLI, keep
+	// the reported one in the state so the next plan restores the declared issuer
+	if effectiveIssuer != issuer && cnameInCertificates(certificates, cname) {
+		log.Printf("[WARN] cname %s on app %s uses issuer %q instead of %q", cname, app, effectiveIssuer, issuer)
+		issuer = effectiveIssuer
+	}
+
+	d.Set("app", app)
+	d.Set("cname", cname)
+	d.Set("issuer", issuer)
+
+	d.Set("router", usedRouters)
+	d.Set("certificate", usedCertificates)
+	d.Set("ready", len(usedCertificates) > 0)
+	d.Set("covered_cnames", coveredCNames)
+	d.Set("effective_issuer", effectiveIssuer)
+	d.Set("issuer_source", issuerSource)
+
+	return nil
+}
+
+type certificateNotReadyError struct {
+	cname string
+}
+
+func (e *certificateNotReadyError) Error() string {
+	return fmt.Sprintf("certificate of cname %s is not ready yet", e.cname)
+}
+
+type certificateCleanupPendingError struct {
+	cname   string
+	routers []string
+}
+
+func (e *certificateCleanupPendingError) Error() string {
+	return fmt.Sprintf("routers %s still report the certificate of cname %s", strings.Join(e.routers, ", "), e.cname)
+}
+
+func filterCertificateRouters(certificates tsuru.AppCertificates, routers []interface{}) tsuru.AppCertificates {
+	if len(routers) == 0 {
+		return certificates
+	}
+
+	filtered := tsuru.AppCertificates{
+		Routers: map[string]tsuru.AppCertificatesRouters{},
+	}
+	for _, router := range routers {
+		routerName := router.(string)
+		if routerCertificates, ok := certificates.Routers[routerName]; ok {
+			filtered.Routers[routerName] = routerCertificates
+		}
+	}
+
+	return filtered
+}
+
+func cnameInCertificates(certificates tsuru.AppCertificates, cname string) bool {
+	for _, router := range certificates.Routers {
+		if _, ok := router.Cnames[cname]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func certificatesByIssuer(certificates tsuru.AppCertificates, cname, issuer string) ([]string, []string) {
 	usedRouters := []string{}
 	usedCertificates := []string{}
 
@@ -149,13 +464,78 @@ func resourceTsuruCertificateIssuerRead(ctx context.Context, d *schema.ResourceD
 	sort.Strings(usedRouters)
 	sort.Strings(usedCertificates)
 
-	d.Set("app", app)
-	d.Set("cname", cname)
-	d.Set("issuer", issuer)
+	return usedRouters, usedCertificates
+}
 
-	d.Set("router", usedRouters)
-	d.Set("certificate", usedCertificates)
-	d.Set("ready", len(usedCertificates) > 0)
+// effectiveCertificateIssuer returns the issuer that routers use for the
+// cname and where it comes from: "app" when it is the issuer set on the app,
+// "router" when routers report another issuer, inherited from defaults
+// configured outside of the app. tsuru API does not tell whether these
+// defaults come from the pool or from the cluster.
+func effectiveCertificateIssuer(certificates tsuru.AppCertificates, cname, issuer string) (string, string) {
+	inherited := []string{}
+	for _, router := range certificates.Routers {
+		cnameInRouter, ok := router.Cnames[cname]
+		if !ok || cnameInRouter.Issuer == "" {
+			continue
+		}
+		if cnameInRouter.Issuer == issuer {
+			return issuer, "app"
+		}
+		inherited = append(inherited, cnameInRouter.Issuer)
+	}
 
-	return nil
+	if len(inherited) == 0 {
+		return "", ""
+	}
+
+	return uniqueSortedStrings(inherited)[0], "router"
+}
+
+func certificateDNSNames(certificate string) ([]string, error) {
+	cert, err := parseLeafCertificate(certificate)
+	if err != nil {
+		return nil, err
+	}
+
+	dnsNames := append([]string{}, cert.DNSNames...)
+	if len(dnsNames) == 0 && cert.Subject.CommonName != "" {
+		dnsNames = append(dnsNames, cert.Subject.CommonName)
+	}
+
+	if len(dnsNames) == 0 {
+		return nil, errors.New("no DNS names found in PEM certificate")
+	}
+
+	return dnsNames, nil
+}
+
+// parseLeafCertificate returns the first certificate of the PEM bundle, only
+// the leaf certificate matters, the remaining are the chain.
+func parseLeafCertificate(certificate string) (*x509.Certificate, error) {
+	rest := []byte(certificate)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return nil, errors.New("no certificate found in PEM")
+		}
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+}
+
+func uniqueSortedStrings(items []string) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, item := range items {
+		if seen[item] {
+			continue
+		}
+		seen[item] = true
+		result = append(result, item)
+	}
+	sort.Strings(result)
+	return result
 }
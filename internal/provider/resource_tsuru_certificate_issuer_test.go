@@ -5,13 +5,27 @@
 package provider
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	echo "github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -19,6 +33,8 @@ import (
 )
 
 func TestAccTsuruCertificateIssuer(t *testing.T) {
+	certificate := testGenerateCertificatePEM(t, "my-cname.org", "www.my-cname.org")
+
 	fakeServer := echo.New()
 	fakeServer.PUT("/1.24/apps/:app/certissuer", func(c echo.Context) error {
 		p := &tsuru.CertIssuerSetData{}
@@ -46,7 +62,7 @@ func TestAccTsuruCertificateIssuer(t *testing.T) {
 					Cnames: map[string]tsuru.AppCertificatesCnames{
 						"my-cname.org": {
 							Issuer:      "lets-encrypt",
-							Certificate: "123",
+							Certificate: certificate,
 						},
 
 						"other-cname.org": {
@@ -94,6 +110,12 @@ func TestAccTsuruCertificateIssuer(t *testing.T) {
 					resource.TestCheckResourceAttr(resourceName, "app", "my-app"),
 					resource.TestCheckResourceAttr(resourceName, "cname", "my-cname.org"),
 					resource.TestCheckResourceAttr(resourceName, "issuer", "lets-encrypt"),
+					resource.TestCheckResourceAttr(resourceName, "ready", "true"),
+					resource.TestCheckResourceAttr(resourceName, "covered_cnames.#", "2"),
+					resource.TestCheckResourceAttr(resourceName, "covered_cnames.0", "my-cname.org"),
+					resource.TestCheckResourceAttr(resourceName, "covered_cnames.1", "www.my-cname.org"),
+					resource.TestCheckResourceAttr(resourceName, "effective_issuer", "lets-encrypt"),
+					resource.TestCheckResourceAttr(resourceName, "issuer_source", "app"),
 				),
 			},
 		},
@@ -109,3 +131,499 @@ resource "tsuru_certificate_issuer" "cert" {
 }
 `, app, cname, issuer)
 }
+
+func TestAccTsuruCertificateIssuerWaitForReadyDNS01(t *testing.T) {
+	certificate := testGenerateCertificatePEM(t, "new-cname.org")
+
+	fakeServer := echo.New()
+	fakeServer.PUT("/1.24/apps/:app/certissuer", func(c echo.Context) error {
+		p := &tsuru.CertIssuerSetData{}
+		err := c.Bind(p)
+		require.NoError(t, err)
+		assert.Equal(t, "new-cname.org", p.Cname)
+		assert.Equal(t, "dns01-issuer", p.Issuer)
+
+		return nil
+	})
+	fakeServer.DELETE("/1.24/apps/:app/certissuer", func(c echo.Context) error {
+		return nil
+	})
+
+	iterationCount := 0
+	fakeServer.GET("/1.24/apps/:app/certificate", func(c echo.Context) error {
+		iterationCount++
+		cnameCertificate := tsuru.AppCertificatesCnames{Issuer: "dns01-issuer"}
+		if iterationCount > 2 {
+			cnameCertificate.Certificate = certificate
+		}
+
+		return c.JSON(http.StatusOK, tsuru.AppCertificates{
+			Routers: map[string]tsuru.AppCertificatesRouters{
+				"https-router": {
+					Cnames: map[string]tsuru.AppCertificatesCnames{
+						"new-cname.org": cnameCertificate,
+					},
+				},
+			},
+		})
+	})
+
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("method=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	resourceName := "tsuru_certificate_issuer.cert"
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      nil,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "tsuru_certificate_issuer" "cert" {
+	app            = "my-app"
+	cname          = "new-cname.org"
+	issuer         = "dns01-issuer"
+	wait_for_ready = true
+	expect_http01  = false
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccResourceExists(resourceName),
+					resource.TestCheckResourceAttr(resourceName, "ready", "true"),
+					resource.TestCheckResourceAttr(resourceName, "covered_cnames.0", "new-cname.org"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccTsuruCertificateIssuerRoutersFilter(t *testing.T) {
+	certificate := testGenerateCertificatePEM(t, "my-cname.org")
+
+	fakeServer := echo.New()
+	fakeServer.PUT("/1.24/apps/:app/certissuer", func(c echo.Context) error {
+		return nil
+	})
+	fakeServer.DELETE("/1.24/apps/:app/certissuer", func(c echo.Context) error {
+		return nil
+	})
+
+	iterationCount := 0
+	fakeServer.GET("/1.24/apps/:app/certificate", func(c echo.Context) error {
+		iterationCount++
+
+		return c.JSON(http.StatusOK, tsuru.AppCertificates{
+			Routers: map[string]tsuru.AppCertificatesRouters{
+				"https-router": {
+					Cnames: map[string]tsuru.AppCertificatesCnames{
+						"my-cname.org": {
+							Issuer:      "lets-encrypt",
+							Certificate: certificate,
+						},
+					},
+				},
+				"foreign-router": {
+					Cnames: map[string]tsuru.AppCertificatesCnames{
+						"my-cname.org": {
+							Issuer:      "lets-encrypt",
+							Certificate: testGenerateCertificatePEM(t, fmt.Sprintf("foreign-%d.my-cname.org", iterationCount)),
+						},
+					},
+				},
+			},
+		})
+	})
+
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("method=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	config := `
+resource "tsuru_certificate_issuer" "cert" {
+	app     = "my-app"
+	cname   = "my-cname.org"
+	issuer  = "lets-encrypt"
+	routers = ["https-router"]
+}
+`
+	checks := resource.ComposeAggregateTestCheckFunc(
+		resource.TestCheckResourceAttr("tsuru_certificate_issuer.cert", "router.#", "1"),
+		resource.TestCheckResourceAttr("tsuru_certificate_issuer.cert", "router.0", "https-router"),
+		resource.TestCheckResourceAttr("tsuru_certificate_issuer.cert", "certificate.#", "1"),
+		resource.TestCheckResourceAttr("tsuru_certificate_issuer.cert", "certificate.0", certificate),
+		resource.TestCheckResourceAttr("tsuru_certificate_issuer.cert", "covered_cnames.#", "1"),
+		resource.TestCheckResourceAttr("tsuru_certificate_issuer.cert", "covered_cnames.0", "my-cname.org"),
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      nil,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check:  checks,
+			},
+			{
+				Config: config,
+				Check:  checks,
+			},
+		},
+	})
+}
+
+func TestAccTsuruCertificateIssuerExternalDrift(t *testing.T) {
+	certificate := testGenerateCertificatePEM(t, "my-cname.org")
+
+	currentIssuer := ""
+	setIssuerCount := 0
+
+	fakeServer := echo.New()
+	fakeServer.PUT("/1.24/apps/:app/certissuer", func(c echo.Context) error {
+		p := &tsuru.CertIssuerSetData{}
+		err := c.Bind(p)
+		require.NoError(t, err)
+		assert.Equal(t, "lets-encrypt", p.Issuer)
+
+		setIssuerCount++
+		currentIssuer = p.Issuer
+		return nil
+	})
+	fakeServer.DELETE("/1.24/apps/:app/certissuer", func(c echo.Context) error {
+		currentIssuer = ""
+		return nil
+	})
+	fakeServer.GET("/1.24/apps/:app/certificate", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, tsuru.AppCertificates{
+			Routers: map[string]tsuru.AppCertificatesRouters{
+				"https-router": {
+					Cnames: map[string]tsuru.AppCertificatesCnames{
+						"my-cname.org": {
+							Issuer:      currentIssuer,
+							Certificate: certificate,
+						},
+					},
+				},
+			},
+		})
+	})
+
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("method=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	resourceName := "tsuru_certificate_issuer.cert"
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      nil,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTsuruCertificateIssuer("my-app", "my-cname.org", "lets-encrypt"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccResourceExists(resourceName),
+					resource.TestCheckResourceAttr(resourceName, "issuer", "lets-encrypt"),
+				),
+			},
+			{
+				// someone sets another issuer with tsuru CLI
+				PreConfig: func() {
+					currentIssuer = "self-signed"
+				},
+				Config:             testAccTsuruCertificateIssuer("my-app", "my-cname.org", "lets-encrypt"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccTsuruCertificateIssuer("my-app", "my-cname.org", "lets-encrypt"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "issuer", "lets-encrypt"),
+					resource.TestCheckResourceAttr(resourceName, "effective_issuer", "lets-encrypt"),
+					func(s *terraform.State) error {
+						if setIssuerCount != 2 {
+							return fmt.Errorf("expected the issuer to be restored, it was set %d times", setIssuerCount)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestWaitForCertificateIssuerReadyHTTP01Unresolvable(t *testing.T) {
+	originalLookupHost := certificateIssuerLookupHost
+	defer func() { certificateIssuerLookupHost = originalLookupHost }()
+	certificateIssuerLookupHost = func(ctx context.Context, host string) ([]string, error) {
+		assert.Equal(t, "new-cname.org", host)
+		return nil, errors.New("no such host")
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceTsuruCertificateIssuer().Schema, map[string]interface{}{
+		"app":            "my-app",
+		"cname":          "new-cname.org",
+		"issuer":         "lets-encrypt",
+		"wait_for_ready": true,
+	})
+
+	diags := waitForCertificateIssuerReady(context.Background(), d, nil, "my-app", "new-cname.org", "lets-encrypt")
+	require.Len(t, diags, 1)
+	assert.Equal(t, diag.Warning, diags[0].Severity)
+	assert.Contains(t, diags[0].Detail, "expect_http01 = false")
+}
+
+func TestResourceTsuruCertificateIssuerUnsetRemoveCertificate(t *testing.T) {
+	for _, removeCertificate := range []bool{true, false} {
+		calls := []string{}
+
+		fakeServer := echo.New()
+		fakeServer.DELETE("/1.24/apps/:app/certissuer", func(c echo.Context) error {
+			assert.Equal(t, "my-app", c.Param("app"))
+			assert.Equal(t, "my-cname.org", c.QueryParam("cname"))
+			calls = append(calls, "unset issuer")
+			return nil
+		})
+		fakeServer.DELETE("/1.24/apps/:app/certificate", func(c echo.Context) error {
+			assert.Equal(t, "my-app", c.Param("app"))
+			assert.Equal(t, "my-cname.org", c.QueryParam("cname"))
+			assert.Equal(t, "my-token", c.Request().Header.Get("Authorization"))
+			calls = append(calls, "unset certificate")
+			return nil
+		})
+		fakeServer.GET("/1.24/apps/:app/certificate", func(c echo.Context) error {
+			return c.JSON(http.StatusOK, tsuru.AppCertificates{})
+		})
+		fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+			t.Errorf("method=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+		}
+
+		server := httptest.NewServer(fakeServer)
+
+		cfg := tsuru.NewConfiguration()
+		cfg.BasePath = server.URL
+		provider := &tsuruProvider{
+			Host:        server.URL,
+			Token:       "my-token",
+			HTTPClient:  http.DefaultClient,
+			TsuruClient: tsuru.NewAPIClient(cfg),
+		}
+
+		d := schema.TestResourceDataRaw(t, resourceTsuruCertificateIssuer().Schema, map[string]interface{}{
+			"app":                         "my-app",
+			"cname":                       "my-cname.org",
+			"issuer":                      "lets-encrypt",
+			"remove_certificate_on_unset": removeCertificate,
+		})
+		d.SetId("my-app::my-cname.org::lets-encrypt")
+
+		diags := resourceTsuruCertificateIssuerUnset(context.Background(), d, provider)
+		require.False(t, diags.HasError(), "%v", diags)
+		server.Close()
+
+		if removeCertificate {
+			assert.Equal(t, []string{"unset issuer", "unset certificate"}, calls)
+		} else {
+			assert.Equal(t, []string{"unset issuer"}, calls)
+		}
+	}
+}
+
+func TestResourceTsuruCertificateIssuerUnsetRemoveCertificateErrors(t *testing.T) {
+	certificateCalls := 0
+
+	fakeServer := echo.New()
+	fakeServer.DELETE("/1.24/apps/:app/certissuer", func(c echo.Context) error {
+		return nil
+	})
+	fakeServer.DELETE("/1.24/apps/:app/certificate", func(c echo.Context) error {
+		certificateCalls++
+		if certificateCalls == 1 {
+			return c.String(http.StatusConflict, "event locked: app my-app")
+		}
+		return c.String(http.StatusBadRequest, "Certificate not found")
+	})
+	fakeServer.GET("/1.24/apps/:app/certificate", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, tsuru.AppCertificates{})
+	})
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("method=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+
+	server := httptest.NewServer(fakeServer)
+	defer server.Close()
+
+	cfg := tsuru.NewConfiguration()
+	cfg.BasePath = server.URL
+	provider := &tsuruProvider{
+		Host:        server.URL,
+		Token:       "my-token",
+		HTTPClient:  http.DefaultClient,
+		TsuruClient: tsuru.NewAPIClient(cfg),
+		Retry:       retrySettings{MaxAttempts: 3, Delay: time.Millisecond},
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceTsuruCertificateIssuer().Schema, map[string]interface{}{
+		"app":                         "my-app",
+		"cname":                       "my-cname.org",
+		"issuer":                      "lets-encrypt",
+		"remove_certificate_on_unset": true,
+	})
+	d.SetId("my-app::my-cname.org::lets-encrypt")
+
+	diags := resourceTsuruCertificateIssuerUnset(context.Background(), d, provider)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, 2, certificateCalls)
+}
+
+func TestResourceTsuruCertificateIssuerUnsetWaitForCleanup(t *testing.T) {
+	var mu sync.Mutex
+	certificateReads := 0
+
+	fakeServer := echo.New()
+	fakeServer.DELETE("/1.24/apps/:app/certissuer", func(c echo.Context) error {
+		return nil
+	})
+	fakeServer.GET("/1.24/apps/:app/certificate", func(c echo.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		certificateReads++
+
+		cnameInRouter := tsuru.AppCertificatesCnames{}
+		if certificateReads < 3 {
+			cnameInRouter = tsuru.AppCertificatesCnames{Issuer: "lets-encrypt", Certificate: "123"}
+		}
+		return c.JSON(http.StatusOK, tsuru.AppCertificates{
+			Routers: map[string]tsuru.AppCertificatesRouters{
+				"https-router": {Cnames: map[string]tsuru.AppCertificatesCnames{"my-cname.org": cnameInRouter}},
+			},
+		})
+	})
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("method=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+
+	server := httptest.NewServer(fakeServer)
+	defer server.Close()
+
+	cfg := tsuru.NewConfiguration()
+	cfg.BasePath = server.URL
+	provider := &tsuruProvider{TsuruClient: tsuru.NewAPIClient(cfg)}
+
+	d := schema.TestResourceDataRaw(t, resourceTsuruCertificateIssuer().Schema, map[string]interface{}{
+		"app":              "my-app",
+		"cname":            "my-cname.org",
+		"issuer":           "lets-encrypt",
+		"wait_for_cleanup": true,
+	})
+	d.SetId("my-app::my-cname.org::lets-encrypt")
+
+	diags := resourceTsuruCertificateIssuerUnset(context.Background(), d, provider)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	mu.Lock()
+	defer mu.Unlock()
+	// two reads while the cleanup is pending, one when it is done and the
+	// last one from the refresh after the unset
+	assert.Equal(t, 4, certificateReads)
+}
+
+func TestWaitForCertificateCleanupStalled(t *testing.T) {
+	fakeServer := echo.New()
+	fakeServer.GET("/1.24/apps/:app/certificate", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, tsuru.AppCertificates{
+			Routers: map[string]tsuru.AppCertificatesRouters{
+				"https-router": {Cnames: map[string]tsuru.AppCertificatesCnames{"my-cname.org": {Certificate: "123"}}},
+				"other-router": {Cnames: map[string]tsuru.AppCertificatesCnames{"my-cname.org": {}}},
+			},
+		})
+	})
+
+	server := httptest.NewServer(fakeServer)
+	defer server.Close()
+
+	cfg := tsuru.NewConfiguration()
+	cfg.BasePath = server.URL
+	provider := &tsuruProvider{TsuruClient: tsuru.NewAPIClient(cfg)}
+
+	err := waitForCertificateCleanup(context.Background(), provider, "my-app", "my-cname.org", "lets-encrypt", nil, false, time.Second)
+	require.NoError(t, err)
+
+	err = waitForCertificateCleanup(context.Background(), provider, "my-app", "my-cname.org", "lets-encrypt", nil, true, time.Second)
+	var pendingErr *certificateCleanupPendingError
+	require.ErrorAs(t, err, &pendingErr)
+	assert.Equal(t, "routers https-router still report the certificate of cname my-cname.org", pendingErr.Error())
+}
+
+func TestEffectiveCertificateIssuer(t *testing.T) {
+	certificates := tsuru.AppCertificates{
+		Routers: map[string]tsuru.AppCertificatesRouters{
+			"https-router": {
+				Cnames: map[string]tsuru.AppCertificatesCnames{
+					"my-cname.org":    {Issuer: "pool-issuer"},
+					"other-cname.org": {},
+				},
+			},
+			"other-https-router": {
+				Cnames: map[string]tsuru.AppCertificatesCnames{
+					"my-cname.org": {Issuer: "lets-encrypt"},
+				},
+			},
+		},
+	}
+
+	issuer, source := effectiveCertificateIssuer(certificates, "my-cname.org", "lets-encrypt")
+	assert.Equal(t, "lets-encrypt", issuer)
+	assert.Equal(t, "app", source)
+
+	issuer, source = effectiveCertificateIssuer(certificates, "my-cname.org", "self-signed")
+	assert.Equal(t, "lets-encrypt", issuer)
+	assert.Equal(t, "router", source)
+
+	issuer, source = effectiveCertificateIssuer(certificates, "other-cname.org", "lets-encrypt")
+	assert.Equal(t, "", issuer)
+	assert.Equal(t, "", source)
+}
+
+func TestCertificateDNSNames(t *testing.T) {
+	certificate := testGenerateCertificatePEM(t, "b.my-cname.org", "a.my-cname.org")
+
+	dnsNames, err := certificateDNSNames(certificate)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b.my-cname.org", "a.my-cname.org"}, dnsNames)
+
+	_, err = certificateDNSNames("123")
+	assert.Error(t, err)
+}
+
+func TestUniqueSortedStrings(t *testing.T) {
+	assert.Equal(t, []string{"a", "b", "c"}, uniqueSortedStrings([]string{"c", "a", "b", "a"}))
+	assert.Equal(t, []string{}, uniqueSortedStrings(nil))
+}
+
+func testGenerateCertificatePEM(t *testing.T, dnsNames ...string) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
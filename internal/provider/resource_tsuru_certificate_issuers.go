@@ -0,0 +1,231 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
+)
+
+const defaultCertificateIssuerAttemptTimeout = 10 * time.Minute
+
+func resourceTsuruCertificateIssuers() *schema.Resource {
+	return &schema.Resource{
+		Description: "Set the first issuer of a prioritized list able to generate the certificate of a cname of a tsuru application. " +
+			"Each issuer is set in order and the next one is tried when the certificate is not ready within `attempt_timeout`",
+		CreateContext: resourceTsuruCertificateIssuersSet,
+		ReadContext:   resourceTsuruCertificateIssuersRead,
+		UpdateContext: resourceTsuruCertificateIssuersUpdate,
+		DeleteContext: resourceTsuruCertificateIssuersUnset,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(60 * time.Minute),
+			Update: schema.DefaultTimeout(60 * time.Minute),
+			Delete: schema.DefaultTimeout(60 * time.Minute),
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"app": {
+				Type:        schema.TypeString,
+				Description: "Application name",
+				Required:    true,
+				ForceNew:    true,
+			},
+
+			"cname": {
+				Type:        schema.TypeString,
+				Description: "Application CNAME",
+				Required:    true,
+				ForceNew:    true,
+			},
+
+			"issuers": {
+				Type:        schema.TypeList,
+				Description: "Certificate issuers to try, in order of preference. When the cname uses an issuer out of the list, set outside of terraform, the issuers are tried again",
+				Required:    true,
+				MinItems:    1,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+
+			"attempt_timeout": {
+				Type:         schema.TypeString,
+				Description:  "Maximum time to wait for the certificate of each issuer before trying the next one, like `10m`, defaults to `10m`",
+				Optional:     true,
+				ValidateFunc: validateDuration,
+			},
+
+			"routers": {
+				Type:        schema.TypeList,
+				Description: "Only reconcile the certificate on these routers, ignoring routers managed outside of terraform. All routers of the app are considered when unset",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+
+			"issuer": {
+				Type:        schema.TypeString,
+				Description: "Issuer set for the cname, the one that generated the certificate when it is ready",
+				Computed:    true,
+			},
+
+			"ready": {
+				Type:        schema.TypeBool,
+				Description: "If the certificate is ready",
+				Computed:    true,
+			},
+
+			"router": {
+				Type:        schema.TypeList,
+				Description: "Routers that are using the certificate",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+
+			"certificate": {
+				Type:        schema.TypeList,
+				Description: "Certificate Generated by Issuer, filled after the certificate is ready",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceTsuruCertificateIssuersSet(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	provider := meta.(*tsuruProvider)
+
+	app := d.Get("app").(string)
+	cname := d.Get("cname").(string)
+	routers := d.Get("routers").([]interface{})
+
+	attemptTimeout := defaultCertificateIssuerAttemptTimeout
+	if v, ok := d.GetOk("attempt_timeout"); ok {
+		attemptTimeout, _ = time.ParseDuration(v.(string))
+	}
+
+	var diags diag.Diagnostics
+	failed := []string{}
+	for _, item := range d.Get("issuers").([]interface{}) {
+		issuer := item.(string)
+
+		_, err := provider.TsuruClient.AppApi.AppSetCertIssuer(ctx, app, tsuru.CertIssuerSetData{
+			Cname:  cname,
+			Issuer: issuer,
+		})
+		if err != nil {
+			return append(diags, diag.Errorf("unable to set certificate issuer %s: %v", issuer, err)...)
+		}
+
+		d.SetId(createID([]string{app, cname}))
+		d.Set("issuer", issuer)
+
+		err = waitForCertificateIssued(ctx, provider, app, cname, issuer, routers, attemptTimeout)
+		if err == nil {
+			log.Printf("[INFO] certificate of cname %s on app %s issued by %s", cname, app, issuer)
+			return append(diags, resourceTsuruCertificateIssuersRead(ctx, d, meta)...)
+		}
+
+		// the issuer did not generate the certificate in time, any other error
+		// comes from tsuru API and trying the next issuer would not help
+		var notReadyErr *certificateNotReadyError
+		var timeoutErr *resource.TimeoutError
+		if !errors.As(err, &notReadyErr) && !errors.As(err, &timeoutErr) {
+			return append(diags, diag.Errorf("unable to wait for the certificate of cname %s issued by %s: %v", cname, issuer, err)...)
+		}
+
+		failed = append(failed, issuer)
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("issuer %s did not generate the certificate of cname %s", issuer, cname),
+			Detail:   fmt.Sprintf("the certificate was not ready after %s", attemptTimeout),
+		})
+	}
+
+	// the last issuer stays set, it may still generate the certificate later
+	diags = append(diags, diag.Diagnostic{
+		Severity: diag.Error,
+		Summary:  fmt.Sprintf("no issuer generated the certificate of cname %s", cname),
+		Detail:   fmt.Sprintf("issuers tried: %s", strings.Join(failed, ", ")),
+	})
+
+	return append(diags, resourceTsuruCertificateIssuersRead(ctx, d, meta)...)
+}
+
+// resourceTsuruCertificateIssuersUpdate tries the issuers again only when the
+// list changes, keeping a fallback issuer that already works otherwise.
+func resourceTsuruCertificateIssuersUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if d.HasChange("issuers") {
+		return resourceTsuruCertificateIssuersSet(ctx, d, meta)
+	}
+	return resourceTsuruCertificateIssuersRead(ctx, d, meta)
+}
+
+func resourceTsuruCertificateIssuersRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	provider := meta.(*tsuruProvider)
+	parts, err := IDtoParts(d.Id(), 2)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	app := parts[0]
+	cname := parts[1]
+
+	certificates, _, err := provider.TsuruClient.AppApi.AppGetCertificates(ctx, app)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	certificates = filterCertificateRouters(certificates, d.Get("routers").([]interface{}))
+
+	issuers := []string{}
+	for _, item := range d.Get("issuers").([]interface{}) {
+		issuers = append(issuers, item.(string))
+	}
+
+	effectiveIssuer, _ := effectiveCertificateIssuer(certificates, cname, d.Get("issuer").(string))
+
+	// the issuer was changed outside of terraform, e.g. with tsuru CLI, keep
+	// the reported one as the list so the next plan tries the issuers again
+	if effectiveIssuer != "" && !slices.Contains(issuers, effectiveIssuer) {
+		log.Printf("[WARN] cname %s on app %s uses issuer %q out of %v", cname, app, effectiveIssuer, issuers)
+		issuers = []string{effectiveIssuer}
+	}
+
+	usedRouters, usedCertificates := certificatesByIssuer(certificates, cname, effectiveIssuer)
+
+	d.Set("app", app)
+	d.Set("cname", cname)
+	d.Set("issuers", issuers)
+	d.Set("issuer", effectiveIssuer)
+	d.Set("router", usedRouters)
+	d.Set("certificate", usedCertificates)
+	d.Set("ready", len(usedCertificates) > 0)
+
+	return nil
+}
+
+func resourceTsuruCertificateIssuersUnset(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	provider := meta.(*tsuruProvider)
+	parts, err := IDtoParts(d.Id(), 2)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	_, err = provider.TsuruClient.AppApi.AppUnsetCertIssuer(ctx, parts[0], parts[1])
+	if err != nil {
+		return diag.Errorf("unable to unset certificate issuer: %v", err)
+	}
+
+	return nil
+}
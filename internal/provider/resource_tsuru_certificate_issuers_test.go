@@ -0,0 +1,172 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
+)
+
+// testCertificateIssuersServer fakes an app whose cname only gets a
+// certificate from the issuers in working.
+func testCertificateIssuersServer(t *testing.T, certificate string, working ...string) (*echo.Echo, func() []string) {
+	var mu sync.Mutex
+	current := ""
+	setIssuers := []string{}
+
+	fakeServer := echo.New()
+	fakeServer.PUT("/1.24/apps/:app/certissuer", func(c echo.Context) error {
+		p := &tsuru.CertIssuerSetData{}
+		err := c.Bind(p)
+		require.NoError(t, err)
+		assert.Equal(t, "my-app", c.Param("app"))
+		assert.Equal(t, "my-cname.org", p.Cname)
+
+		mu.Lock()
+		defer mu.Unlock()
+		current = p.Issuer
+		setIssuers = append(setIssuers, p.Issuer)
+		return nil
+	})
+
+	fakeServer.DELETE("/1.24/apps/:app/certissuer", func(c echo.Context) error {
+		assert.Equal(t, "my-cname.org", c.QueryParam("cname"))
+		return nil
+	})
+
+	fakeServer.GET("/1.24/apps/:app/certificate", func(c echo.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		cnameInRouter := tsuru.AppCertificatesCnames{Issuer: current}
+		for _, issuer := range working {
+			if issuer == current {
+				cnameInRouter.Certificate = certificate
+			}
+		}
+
+		return c.JSON(http.StatusOK, tsuru.AppCertificates{
+			Routers: map[string]tsuru.AppCertificatesRouters{
+				"https-router": {
+					Cnames: map[string]tsuru.AppCertificatesCnames{"my-cname.org": cnameInRouter},
+				},
+			},
+		})
+	})
+
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("method=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+
+	return fakeServer, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, setIssuers...)
+	}
+}
+
+func TestAccTsuruCertificateIssuers(t *testing.T) {
+	certificate := testGenerateCertificatePEM(t, "my-cname.org")
+	fakeServer, _ := testCertificateIssuersServer(t, certificate, "backup-ca")
+
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	resourceName := "tsuru_certificate_issuers.cert"
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      nil,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "tsuru_certificate_issuers" "cert" {
+	app             = "my-app"
+	cname           = "my-cname.org"
+	issuers         = ["flaky-acme", "backup-ca"]
+	attempt_timeout = "2s"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccResourceExists(resourceName),
+					resource.TestCheckResourceAttr(resourceName, "issuers.#", "2"),
+					resource.TestCheckResourceAttr(resourceName, "issuer", "backup-ca"),
+					resource.TestCheckResourceAttr(resourceName, "ready", "true"),
+					resource.TestCheckResourceAttr(resourceName, "router.0", "https-router"),
+				),
+			},
+		},
+	})
+}
+
+func TestResourceTsuruCertificateIssuersFallback(t *testing.T) {
+	certificate := testGenerateCertificatePEM(t, "my-cname.org")
+	fakeServer, setIssuers := testCertificateIssuersServer(t, certificate, "backup-ca")
+
+	server := httptest.NewServer(fakeServer)
+	defer server.Close()
+
+	cfg := tsuru.NewConfiguration()
+	cfg.BasePath = server.URL
+	provider := &tsuruProvider{TsuruClient: tsuru.NewAPIClient(cfg)}
+
+	d := schema.TestResourceDataRaw(t, resourceTsuruCertificateIssuers().Schema, map[string]interface{}{
+		"app":             "my-app",
+		"cname":           "my-cname.org",
+		"issuers":         []interface{}{"flaky-acme", "backup-ca", "never-tried"},
+		"attempt_timeout": "1s",
+	})
+
+	diags := resourceTsuruCertificateIssuersSet(context.Background(), d, provider)
+	require.False(t, diags.HasError(), "%v", diags)
+	require.Len(t, diags, 1)
+	assert.Equal(t, diag.Warning, diags[0].Severity)
+	assert.Equal(t, "issuer flaky-acme did not generate the certificate of cname my-cname.org", diags[0].Summary)
+
+	assert.Equal(t, []string{"flaky-acme", "backup-ca"}, setIssuers())
+	assert.Equal(t, "my-app::my-cname.org", d.Id())
+	assert.Equal(t, "backup-ca", d.Get("issuer"))
+	assert.Equal(t, true, d.Get("ready"))
+	assert.Equal(t, []interface{}{"flaky-acme", "backup-ca", "never-tried"}, d.Get("issuers"))
+}
+
+func TestResourceTsuruCertificateIssuersAllFailed(t *testing.T) {
+	fakeServer, setIssuers := testCertificateIssuersServer(t, "")
+
+	server := httptest.NewServer(fakeServer)
+	defer server.Close()
+
+	cfg := tsuru.NewConfiguration()
+	cfg.BasePath = server.URL
+	provider := &tsuruProvider{TsuruClient: tsuru.NewAPIClient(cfg)}
+
+	d := schema.TestResourceDataRaw(t, resourceTsuruCertificateIssuers().Schema, map[string]interface{}{
+		"app":             "my-app",
+		"cname":           "my-cname.org",
+		"issuers":         []interface{}{"flaky-acme", "backup-ca"},
+		"attempt_timeout": "1s",
+	})
+
+	diags := resourceTsuruCertificateIssuersSet(context.Background(), d, provider)
+	require.True(t, diags.HasError())
+	assert.Equal(t, "no issuer generated the certificate of cname my-cname.org", diags[len(diags)-1].Summary)
+	assert.Equal(t, "issuers tried: flaky-acme, backup-ca", diags[len(diags)-1].Detail)
+
+	assert.Equal(t, []string{"flaky-acme", "backup-ca"}, setIssuers())
+	assert.Equal(t, "backup-ca", d.Get("issuer"))
+	assert.Equal(t, false, d.Get("ready"))
+}
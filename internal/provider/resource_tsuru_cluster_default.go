@@ -0,0 +1,170 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceTsuruClusterDefault() *schema.Resource {
+	return &schema.Resource{
+		Description: "Designate the default cluster of its provisioner, the previous default cluster is restored on destroy. " +
+			"tsuru does not allow a default cluster to have pools, so the pools of the cluster are removed while it is the default and reassigned on destroy. " +
+			"Do not manage `default` of the same cluster with the `tsuru_cluster` resource",
+		CreateContext: resourceTsuruClusterDefaultSet,
+		ReadContext:   resourceTsuruClusterDefaultRead,
+		DeleteContext: resourceTsuruClusterDefaultUnset,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(60 * time.Minute),
+			Delete: schema.DefaultTimeout(60 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"cluster": {
+				Type:        schema.TypeString,
+				Description: "Name of the cluster to be the default",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"previous_default": {
+				Type:        schema.TypeString,
+				Description: "Name of the cluster that was the default before, restored on destroy",
+				Computed:    true,
+			},
+			"previous_pools": {
+				Type:        schema.TypeList,
+				Description: "Pools of the cluster before it became the default, reassigned on destroy",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceTsuruClusterDefaultSet(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	provider := meta.(*tsuruProvider)
+	clusterName := d.Get("cluster").(string)
+
+	cluster, _, err := provider.TsuruClient.ClusterApi.ClusterInfo(ctx, clusterName)
+	if err != nil {
+		return diag.Errorf("Could not read tsuru cluster, err : %s", err.Error())
+	}
+
+	clusters, resp, err := provider.TsuruClient.ClusterApi.ClusterList(ctx)
+	if err != nil && (resp == nil || resp.StatusCode != http.StatusNoContent) {
+		return diag.Errorf("Could not list tsuru clusters, err : %s", err.Error())
+	}
+
+	previousDefault := ""
+	for _, c := range clusters {
+		if c.Default && c.Provisioner == cluster.Provisioner && c.Name != cluster.Name {
+			previousDefault = c.Name
+			break
+		}
+	}
+
+	previousPools := cluster.Pools
+	if cluster.Default {
+		previousPools = nil
+	}
+
+	cluster.Default = true
+	cluster.Pools = nil
+
+	_, err = provider.TsuruClient.ClusterApi.ClusterUpdate(ctx, clusterName, cluster)
+	if err != nil {
+		return diag.Errorf("Could not set tsuru cluster %q as default, err: %s", clusterName, err.Error())
+	}
+
+	d.SetId(clusterName)
+	d.Set("previous_default", previousDefault)
+	d.Set("previous_pools", previousPools)
+
+	return resourceTsuruClusterDefaultRead(ctx, d, meta)
+}
+
+func resourceTsuruClusterDefaultRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	provider := meta.(*tsuruProvider)
+	clusterName := d.Id()
+
+	cluster, _, err := provider.TsuruClient.ClusterApi.ClusterInfo(ctx, clusterName)
+	if err != nil {
+		if isNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.Errorf("Could not read tsuru cluster, err : %s", err.Error())
+	}
+
+	if !cluster.Default {
+		log.Printf("[WARN] cluster %s is not the default anymore", clusterName)
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("cluster", cluster.Name)
+
+	return nil
+}
+
+func resourceTsuruClusterDefaultUnset(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	provider := meta.(*tsuruProvider)
+	clusterName := d.Id()
+	previousDefault := d.Get("previous_default").(string)
+
+	previousPools := []string{}
+	for _, item := range d.Get("previous_pools").([]interface{}) {
+		previousPools = append(previousPools, item.(string))
+	}
+
+	if len(previousPools) > 0 {
+		cluster, _, err := provider.TsuruClient.ClusterApi.ClusterInfo(ctx, clusterName)
+		if err != nil {
+			if isNotFoundError(err) {
+				return nil
+			}
+			return diag.Errorf("Could not read tsuru cluster, err : %s", err.Error())
+		}
+
+		cluster.Default = false
+		cluster.Pools = previousPools
+
+		_, err = provider.TsuruClient.ClusterApi.ClusterUpdate(ctx, clusterName, cluster)
+		if err != nil {
+			return diag.Errorf("Could not reassign pools of tsuru cluster %q, err: %s", clusterName, err.Error())
+		}
+	}
+
+	if previousDefault == "" {
+		if len(previousPools) == 0 {
+			log.Printf("[WARN] cluster %s is kept as default, there is no previous default cluster to restore", clusterName)
+		}
+		return nil
+	}
+
+	cluster, _, err := provider.TsuruClient.ClusterApi.ClusterInfo(ctx, previousDefault)
+	if err != nil {
+		if isNotFoundError(err) {
+			log.Printf("[WARN] previous default cluster %s not found, it will not be restored", previousDefault)
+			return nil
+		}
+		return diag.Errorf("Could not read tsuru cluster, err : %s", err.Error())
+	}
+
+	cluster.Default = true
+	cluster.Pools = nil
+
+	_, err = provider.TsuruClient.ClusterApi.ClusterUpdate(ctx, previousDefault, cluster)
+	if err != nil {
+		return diag.Errorf("Could not restore tsuru cluster %q as default, err: %s", previousDefault, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
+)
+
+func TestAccTsuruClusterDefault_basic(t *testing.T) {
+	var mutex sync.Mutex
+	clusters := map[string]*tsuru.Cluster{
+		"old-default": {Name: "old-default", Provisioner: "kubernetes", Default: true},
+		"new-default": {Name: "new-default", Provisioner: "kubernetes", Pools: []string{"pool-a"}},
+	}
+
+	fakeServer := echo.New()
+	fakeServer.GET("/1.3/provisioner/clusters", func(c echo.Context) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return c.JSON(http.StatusOK, []*tsuru.Cluster{clusters["old-default"], clusters["new-default"]})
+	})
+	fakeServer.GET("/1.8/provisioner/clusters/:name", func(c echo.Context) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return c.JSON(http.StatusOK, clusters[c.Param("name")])
+	})
+	fakeServer.POST("/1.4/provisioner/clusters/:name", func(c echo.Context) error {
+		p := &tsuru.Cluster{}
+		err := c.Bind(p)
+		require.NoError(t, err)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		if p.Default {
+			for _, cluster := range clusters {
+				cluster.Default = false
+			}
+		}
+		clusters[c.Param("name")] = p
+		return c.NoContent(http.StatusOK)
+	})
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	resourceName := "tsuru_cluster_default.default"
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy: func(s *terraform.State) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			require.True(t, clusters["old-default"].Default)
+			require.False(t, clusters["new-default"].Default)
+			require.Equal(t, []string{"pool-a"}, clusters["new-default"].Pools)
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "tsuru_cluster_default" "default" {
+	cluster = "new-default"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccResourceExists(resourceName),
+					resource.TestCheckResourceAttr(resourceName, "cluster", "new-default"),
+					resource.TestCheckResourceAttr(resourceName, "previous_default", "old-default"),
+					resource.TestCheckResourceAttr(resourceName, "previous_pools.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "previous_pools.0", "pool-a"),
+				),
+			},
+		},
+	})
+}
@@ -6,11 +6,14 @@ package provider
 
 import (
 	"context"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/pkg/errors"
 	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
 	tsuru_client "github.com/tsuru/go-tsuruclient/pkg/tsuru"
@@ -86,9 +89,19 @@ func resourceTsuruJob() *schema.Resource {
 			},
 
 			"schedule": {
-				Type:        schema.TypeString,
-				Description: "Cron-like schedule for when the job should be triggered",
+				Type:         schema.TypeString,
+				Description:  "Cron-like schedule for when the job should be triggered, like `*/15 * * * *` or `@daily`. Jobs without schedule are manual, only running when triggered",
+				Optional:     true,
+				ValidateFunc: validateCronSchedule,
+			},
+
+			"triggers": {
+				Type:        schema.TypeMap,
+				Description: "Arbitrary map of values that, when changed, triggers a new execution of the job, like `tsuru job trigger`. The job is also triggered on creation when set",
 				Optional:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
 			},
 
 			"container": {
@@ -120,9 +133,10 @@ func resourceTsuruJob() *schema.Resource {
 			},
 
 			"concurrency_policy": {
-				Type:        schema.TypeString,
-				Description: "Concurrency policy",
-				Optional:    true,
+				Type:         schema.TypeString,
+				Description:  "Concurrency policy, one of `Allow`, `Forbid` or `Replace`",
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"Allow", "Forbid", "Replace"}, false),
 			},
 		},
 	}
@@ -156,6 +170,12 @@ func resourceTsuruJobCreate(ctx context.Context, d *schema.ResourceData, meta in
 
 	d.SetId(job.Name)
 
+	if len(d.Get("triggers").(map[string]interface{})) > 0 {
+		if err = triggerJob(ctx, d, provider, job.Name); err != nil {
+			return diag.Errorf("unable to trigger job %s: %v", job.Name, err)
+		}
+	}
+
 	return resourceTsuruJobRead(ctx, d, meta)
 }
 
@@ -189,9 +209,31 @@ func resourceTsuruJobUpdate(ctx context.Context, d *schema.ResourceData, meta in
 		return diag.Errorf("unable to update job %s: %v", jobName, err)
 	}
 
+	if d.HasChange("triggers") && len(d.Get("triggers").(map[string]interface{})) > 0 {
+		if err = triggerJob(ctx, d, provider, jobName); err != nil {
+			return diag.Errorf("unable to trigger job %s: %v", jobName, err)
+		}
+	}
+
 	return resourceTsuruJobRead(ctx, d, meta)
 }
 
+func triggerJob(ctx context.Context, d *schema.ResourceData, provider *tsuruProvider, name string) error {
+	return resource.RetryContext(ctx, d.Timeout(schema.TimeoutCreate), func() *resource.RetryError {
+		_, err := provider.TsuruClient.JobApi.TriggerJob(ctx, name)
+		if err != nil {
+			var apiError tsuru_client.GenericOpenAPIError
+			if errors.As(err, &apiError) {
+				if isRetryableError(apiError.Body()) {
+					return resource.RetryableError(err)
+				}
+			}
+			return resource.NonRetryableError(err)
+		}
+		return nil
+	})
+}
+
 func resourceTsuruJobRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	provider := meta.(*tsuruProvider)
 	name := d.Id()
@@ -308,6 +350,8 @@ func inputJobFromResourceData(ctx context.Context, d *schema.ResourceData, provi
 
 	if schedule, ok := d.GetOk("schedule"); ok {
 		job.Schedule = schedule.(string)
+	} else {
+		job.Manual = true
 	}
 	if concurrencyPolicyInterface, ok := d.GetOk("concurrency_policy"); ok {
 		concurrencyPolicy := concurrencyPolicyInterface.(string)
@@ -358,6 +402,13 @@ func flattenJobContainer(container tsuru.InputJobContainer) []interface{} {
 func flattenJobSpec(spec tsuru.JobSpec) map[string]any {
 	m := map[string]any{}
 
+	// manual jobs have a fake schedule that never triggers
+	if spec.Manual {
+		m["schedule"] = ""
+	} else {
+		m["schedule"] = spec.Schedule
+	}
+
 	if spec.ConcurrencyPolicy != nil {
 		m["concurrency_policy"] = spec.ConcurrencyPolicy
 	}
@@ -368,3 +419,122 @@ func flattenJobSpec(spec tsuru.JobSpec) map[string]any {
 
 	return m
 }
+
+var cronDescriptors = []string{"@yearly", "@annually", "@monthly", "@weekly", "@daily", "@midnight", "@hourly"}
+
+var cronFields = []struct {
+	name     string
+	min, max int
+	names    []string
+}{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{name: "day of week", min: 0, max: 6, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+// validateCronSchedule accepts the same standard cron expressions as tsuru
+// API: five fields or descriptors like @daily and @every 1h.
+func validateCronSchedule(i interface{}, k string) ([]string, []error) {
+	v, ok := i.(string)
+	if !ok {
+		return nil, []error{errors.Errorf("expected type of %s to be string", k)}
+	}
+
+	if err := parseCronSchedule(v); err != nil {
+		return nil, []error{errors.Errorf("invalid schedule %q for %s: %v", v, k, err)}
+	}
+	return nil, nil
+}
+
+func parseCronSchedule(schedule string) error {
+	fields := strings.Fields(schedule)
+	if len(fields) > 0 && (strings.HasPrefix(fields[0], "TZ=") || strings.HasPrefix(fields[0], "CRON_TZ=")) {
+		fields = fields[1:]
+	}
+
+	if len(fields) == 0 {
+		return errors.New("empty schedule")
+	}
+
+	if strings.HasPrefix(fields[0], "@") {
+		if fields[0] == "@every" {
+			if len(fields) != 2 {
+				return errors.New("@every expects a duration, like @every 1h30m")
+			}
+			_, err := time.ParseDuration(fields[1])
+			return err
+		}
+		for _, descriptor := range cronDescriptors {
+			if fields[0] == descriptor && len(fields) == 1 {
+				return nil
+			}
+		}
+		return errors.Errorf("unknown descriptor %s", fields[0])
+	}
+
+	if len(fields) != len(cronFields) {
+		return errors.Errorf("expected %d fields, found %d", len(cronFields), len(fields))
+	}
+
+	for i, field := range cronFields {
+		for _, part := range strings.Split(fields[i], ",") {
+			if err := parseCronRange(part, field.min, field.max, field.names); err != nil {
+				return errors.Errorf("%s: %v", field.name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func parseCronRange(expr string, min, max int, names []string) error {
+	rangeExpr, step, hasStep := strings.Cut(expr, "/")
+	if hasStep {
+		n, err := strconv.Atoi(step)
+		if err != nil || n <= 0 {
+			return errors.Errorf("invalid step %q", step)
+		}
+	}
+
+	if rangeExpr == "*" || rangeExpr == "?" {
+		return nil
+	}
+
+	low, high, hasHigh := strings.Cut(rangeExpr, "-")
+	start, err := parseCronValue(low, min, max, names)
+	if err != nil {
+		return err
+	}
+	if !hasHigh {
+		return nil
+	}
+
+	end, err := parseCronValue(high, min, max, names)
+	if err != nil {
+		return err
+	}
+	if start > end {
+		return errors.Errorf("beginning of range %s is greater than its end", rangeExpr)
+	}
+
+	return nil
+}
+
+func parseCronValue(value string, min, max int, names []string) (int, error) {
+	for i, name := range names {
+		if strings.EqualFold(value, name) {
+			return min + i, nil
+		}
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, errors.Errorf("invalid value %q", value)
+	}
+	if n < min || n > max {
+		return 0, errors.Errorf("value %d out of range [%d, %d]", n, min, max)
+	}
+	return n, nil
+}
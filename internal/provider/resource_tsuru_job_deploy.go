@@ -5,7 +5,6 @@
 package provider
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"fmt"
@@ -21,7 +20,7 @@ import (
 
 func resourceTsuruJobDeploy() *schema.Resource {
 	return &schema.Resource{
-		Description:   "Perform an job deploy. Currently, only supporting deploys via prebuilt container images; in order to deploy via tsuru platforms please use tsuru-client",
+		Description:   "Perform an job deploy. Currently, only supporting deploys via prebuilt container images; in order to deploy via tsuru platforms please use tsuru-client. A deploy still running when the operation is interrupted or the resource is destroyed is canceled",
 		CreateContext: resourceTsuruJobDeployDo,
 		UpdateContext: resourceTsuruJobDeployDo,
 		ReadContext:   resourceTsuruJobDeployRead,
@@ -59,6 +58,7 @@ func resourceTsuruJobDeploy() *schema.Resource {
 				Description: "Image generated after success of deploy",
 				Computed:    true,
 			},
+			"stream": deployStreamSchema(),
 		},
 	}
 }
@@ -94,8 +94,9 @@ func resourceTsuruJobDeployDo(ctx context.Context, d *schema.ResourceData, meta
 	req.Header.Set("Authorization", token)
 
 	wait := d.Get("wait").(bool)
+	stream := expandDeployStreamSettings(d.Get("stream"))
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := stream.httpClient(provider).Do(req)
 
 	if err != nil {
 		log.Println("[DEBUG] failed to request deploy", err)
@@ -114,17 +115,18 @@ func resourceTsuruJobDeployDo(ctx context.Context, d *schema.ResourceData, meta
 	d.SetId(eventID)
 
 	if wait {
-		scanner := bufio.NewScanner(resp.Body)
-		for scanner.Scan() {
-			log.Println("[DEBUG]", scanner.Text())
-		}
-
-		if err := scanner.Err(); err != nil {
-			log.Fatal("[ERROR]", err)
+		lines, err := consumeDeployStream(resp.Body, stream.IdleTimeout)
+		logOffset := -1
+		if err != nil {
+			log.Printf("[WARN] deploy stream of event %s interrupted, following the deploy through the event: %v", eventID, err)
+			logOffset = lines
 		}
 
-		err = waitForEventComplete(ctx, provider, eventID)
+		_, err = waitForEventComplete(ctx, provider, eventID, logOffset)
 		if err != nil {
+			if ctx.Err() != nil {
+				return deployInterrupted(provider, eventID, err)
+			}
 			return diag.FromErr(err)
 		}
 	}
@@ -170,6 +172,5 @@ func resourceTsuruJobDeployRead(ctx context.Context, d *schema.ResourceData, met
 }
 
 func resourceTsuruJobDeployDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	log.Println("[DEBUG] delete a deploy is a no-op by terraform")
-	return nil
+	return deleteDeploy(ctx, meta.(*tsuruProvider), d.Id())
 }
@@ -5,12 +5,14 @@
 package provider
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	echo "github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -62,6 +64,7 @@ func TestAccResourceTsuruJob(t *testing.T) {
 				Plan:        tsuru.Plan{Name: "c1m1"},
 				Pool:        "prod",
 				Spec: tsuru.JobSpec{
+					Schedule: "* * * * *",
 					Container: tsuru.InputJobContainer{
 						Image: "tsuru/scratch:latest",
 						Command: []string{
@@ -108,6 +111,7 @@ func TestAccResourceTsuruJob(t *testing.T) {
 					resource.TestCheckResourceAttr(resourceName, "plan", "c1m1"),
 					resource.TestCheckResourceAttr(resourceName, "team_owner", "my-team"),
 					resource.TestCheckResourceAttr(resourceName, "pool", "prod"),
+					resource.TestCheckResourceAttr(resourceName, "schedule", "* * * * *"),
 				),
 			},
 		},
@@ -167,6 +171,7 @@ func TestAccResourceTsuruJobComplete(t *testing.T) {
 				Plan:        tsuru.Plan{Name: "c1m1"},
 				Pool:        "prod",
 				Spec: tsuru.JobSpec{
+					Schedule: "* * * * *",
 					Container: tsuru.InputJobContainer{
 						Image: "tsuru/scratch:latest",
 						Command: []string{
@@ -215,12 +220,138 @@ func TestAccResourceTsuruJobComplete(t *testing.T) {
 					resource.TestCheckResourceAttr(resourceName, "plan", "c1m1"),
 					resource.TestCheckResourceAttr(resourceName, "team_owner", "my-team"),
 					resource.TestCheckResourceAttr(resourceName, "pool", "prod"),
+					resource.TestCheckResourceAttr(resourceName, "schedule", "* * * * *"),
 				),
 			},
 		},
 	})
 }
 
+func TestAccResourceTsuruJobManualTrigger(t *testing.T) {
+	fakeServer := echo.New()
+
+	var job *tsuru.Job
+	triggerCount := 0
+
+	fakeServer.GET("/1.0/pools", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, []tsuru.Pool{{Name: "prod"}})
+	})
+
+	fakeServer.GET("/1.0/plans", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, []tsuru.Plan{{Name: "c1m1"}})
+	})
+
+	fakeServer.POST("/1.13/jobs", func(c echo.Context) error {
+		input := tsuru.InputJob{}
+		c.Bind(&input)
+		assert.True(t, input.Manual)
+		assert.Equal(t, "", input.Schedule)
+
+		job = &tsuru.Job{
+			Name:      input.Name,
+			TeamOwner: input.TeamOwner,
+			Plan:      tsuru.Plan{Name: input.Plan},
+			Pool:      input.Pool,
+			Spec: tsuru.JobSpec{
+				Container: input.Container,
+				Manual:    true,
+				Schedule:  "* * 31 2 *",
+			},
+		}
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"status":  "success",
+			"jobName": input.Name,
+		})
+	})
+
+	fakeServer.GET("/1.13/jobs/:name", func(c echo.Context) error {
+		if job == nil || c.Param("name") != job.Name {
+			return c.JSON(http.StatusNotFound, nil)
+		}
+		return c.JSON(http.StatusOK, tsuru.JobInfo{Job: *job})
+	})
+
+	fakeServer.PUT("/1.13/jobs/:name", func(c echo.Context) error {
+		input := tsuru.InputJob{}
+		c.Bind(&input)
+		assert.True(t, input.Manual)
+		return c.JSON(http.StatusOK, nil)
+	})
+
+	fakeServer.POST("/1.13/jobs/:name/trigger", func(c echo.Context) error {
+		assert.Equal(t, "job01", c.Param("name"))
+		triggerCount++
+		return c.JSON(http.StatusOK, nil)
+	})
+
+	fakeServer.DELETE("/1.13/jobs/:name", func(c echo.Context) error {
+		job = nil
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	checkTriggerCount := func(expected int) resource.TestCheckFunc {
+		return func(s *terraform.State) error {
+			if triggerCount != expected {
+				return fmt.Errorf("expected job to be triggered %d times, got %d", expected, triggerCount)
+			}
+			return nil
+		}
+	}
+
+	resourceName := "tsuru_job.job"
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      nil,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceTsuruJob_manual("v1"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccResourceExists(resourceName),
+					resource.TestCheckResourceAttr(resourceName, "schedule", ""),
+					checkTriggerCount(1),
+				),
+			},
+			{
+				Config: testAccResourceTsuruJob_manual("v2"),
+				Check:  checkTriggerCount(2),
+			},
+			{
+				ResourceName:            resourceName,
+				ImportState:             true,
+				ImportStateId:           "job01",
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"triggers"},
+			},
+		},
+	})
+}
+
+func testAccResourceTsuruJob_manual(revision string) string {
+	return fmt.Sprintf(`
+	resource "tsuru_job" "job" {
+		name = "job01"
+		plan = "c1m1"
+		team_owner = "my-team"
+		pool = "prod"
+		container {
+			image   = "tsuru/scratch:latest"
+			command = ["sleep", 600]
+		}
+
+		triggers = {
+			revision = %q
+		}
+	}
+`, revision)
+}
+
 func testAccResourceTsuruJob_basic() string {
 	return `
 	resource "tsuru_job" "job" {
@@ -258,3 +389,34 @@ func testAccResourceTsuruJob_complete() string {
 	}
 `
 }
+
+func TestParseCronSchedule(t *testing.T) {
+	valid := []string{
+		"* * * * *",
+		"*/15 * * * *",
+		"0 8-18/2 * * mon-fri",
+		"30 3 1,15 jan,jul ?",
+		"CRON_TZ=America/Sao_Paulo 0 0 * * *",
+		"@daily",
+		"@every 1h30m",
+	}
+	for _, schedule := range valid {
+		assert.NoError(t, parseCronSchedule(schedule), schedule)
+	}
+
+	invalid := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"*/0 * * * *",
+		"0 18-8 * * *",
+		"0 0 * foo *",
+		"@sometimes",
+		"@every forever",
+	}
+	for _, schedule := range invalid {
+		assert.Error(t, parseCronSchedule(schedule), schedule)
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
+)
+
+const poolConstraintFieldPlan = "plan"
+
+func resourceTsuruPoolDefaultPlan() *schema.Resource {
+	return &schema.Resource{
+		Description: "Default plan of the apps created in a tsuru pool without a plan. " +
+			"tsuru uses the first plan allowed by the `plan` constraint of the pool, so the plan is kept first in that constraint, preserving the other allowed plans. " +
+			"When the pool has no `plan` constraint, only the default plan becomes allowed on the pool. " +
+			"Do not use it along with a `tsuru_pool_constraint` of field `plan` for the same pool, both write the same constraint and overwrite each other on every apply",
+		CreateContext: resourceTsuruPoolDefaultPlanSet,
+		ReadContext:   resourceTsuruPoolDefaultPlanRead,
+		UpdateContext: resourceTsuruPoolDefaultPlanSet,
+		DeleteContext: resourceTsuruPoolDefaultPlanDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(60 * time.Minute),
+			Update: schema.DefaultTimeout(60 * time.Minute),
+			Delete: schema.DefaultTimeout(60 * time.Minute),
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceTsuruPoolDefaultPlanImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"pool": {
+				Type:        schema.TypeString,
+				Description: "Name of the pool",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"plan": {
+				Type:        schema.TypeString,
+				Description: "Name of the plan used by apps created in the pool without a plan",
+				Required:    true,
+			},
+			"previous_plans": {
+				Type:        schema.TypeList,
+				Description: "Plans allowed by the `plan` constraint of the pool before the default plan was set, in their order, restored on destroy. Empty when the pool had no `plan` constraint, which is then removed on destroy",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceTsuruPoolDefaultPlanSet(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	provider := meta.(*tsuruProvider)
+
+	pool := d.Get("pool").(string)
+	plan := d.Get("plan").(string)
+
+	constraint, err := poolPlanConstraint(ctx, provider, pool)
+	if err != nil {
+		return diag.Errorf("Could not list tsuru pool constraints, err: %s", err.Error())
+	}
+
+	values := []string{}
+	if constraint != nil {
+		if constraint.Blacklist {
+			return diag.Errorf("pool %s denies plans with a blacklist constraint, a whitelist of plans is required to choose its default plan", pool)
+		}
+		values = constraint.Values
+	}
+
+	// plans removed from the state, like the previous default, stay allowed
+	constraintSet := tsuru.PoolConstraintSet{
+		PoolExpr: pool,
+		Field:    poolConstraintFieldPlan,
+		Values:   defaultPlanFirst(values, plan),
+	}
+
+	err = tsuruRetry(ctx, provider, d, func() error {
+		_, internalErr := provider.TsuruClient.PoolApi.ConstraintSet(ctx, constraintSet)
+		return internalErr
+	})
+	if err != nil {
+		return diag.Errorf("Could not set default plan %q of tsuru pool %q, err: %s", plan, pool, err.Error())
+	}
+
+	// only the plans allowed before the resource existed are kept
+	if d.Id() == "" {
+		d.Set("previous_plans", values)
+	}
+	d.SetId(pool)
+
+	return resourceTsuruPoolDefaultPlanRead(ctx, d, meta)
+}
+
+func resourceTsuruPoolDefaultPlanRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	provider := meta.(*tsuruProvider)
+	pool := d.Id()
+
+	constraint, err := poolPlanConstraint(ctx, provider, pool)
+	if err != nil {
+		return diag.Errorf("Could not list tsuru pool constraints, err: %s", err.Error())
+	}
+
+	if constraint == nil || constraint.Blacklist || len(constraint.Values) == 0 || strings.Contains(constraint.Values[0], "*") {
+		log.Printf("[WARN] pool %s has no default plan set on its plan constraint, removing from state", pool)
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("pool", pool)
+	d.Set("plan", constraint.Values[0])
+
+	return nil
+}
+
+func resourceTsuruPoolDefaultPlanDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	provider := meta.(*tsuruProvider)
+
+	pool := d.Get("pool").(string)
+	plan := d.Get("plan").(string)
+
+	constraint, err := poolPlanConstraint(ctx, provider, pool)
+	if err != nil {
+		return diag.Errorf("Could not list tsuru pool constraints, err: %s", err.Error())
+	}
+	if constraint == nil || constraint.Blacklist {
+		return nil
+	}
+
+	previousPlans := []string{}
+	for _, item := range d.Get("previous_plans").([]interface{}) {
+		previousPlans = append(previousPlans, item.(string))
+	}
+	values := restorePreviousPlans(constraint.Values, previousPlans, plan)
+
+	err = tsuruRetry(ctx, provider, d, func() error {
+		_, internalErr := provider.TsuruClient.PoolApi.ConstraintSet(ctx, tsuru.PoolConstraintSet{
+			PoolExpr:  pool,
+			Field:     poolConstraintFieldPlan,
+			Values:    values,
+			Blacklist: constraint.Blacklist,
+		})
+		return internalErr
+	})
+	if err != nil {
+		return diag.Errorf("Could not unset default plan %q of tsuru pool %q, err: %s", plan, pool, err.Error())
+	}
+
+	return nil
+}
+
+// resourceTsuruPoolDefaultPlanImport takes the current plans of the pool as
+// the previous ones, so destroying an imported default keeps every plan
+// allowed.
+func resourceTsuruPoolDefaultPlanImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	provider := meta.(*tsuruProvider)
+
+	constraint, err := poolPlanConstraint(ctx, provider, d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	previousPlans := []string{}
+	if constraint != nil && !constraint.Blacklist {
+		previousPlans = constraint.Values
+	}
+	d.Set("previous_plans", previousPlans)
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// poolPlanConstraint returns the plan constraint set exactly for the pool,
+// constraints of glob expressions matching the pool are ignored.
+func poolPlanConstraint(ctx context.Context, provider *tsuruProvider, pool string) (*tsuru.PoolConstraint, error) {
+	constraints, _, err := provider.TsuruClient.PoolApi.ConstraintList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range constraints {
+		if constraints[i].PoolExpr == pool && constraints[i].Field == poolConstraintFieldPlan {
+			return &constraints[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+func defaultPlanFirst(values []string, plan string) []string {
+	result := []string{plan}
+	for _, value := range values {
+		if value != plan {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// restorePreviousPlans undoes the reordering made to set the default plan,
+// plans allowed before keep their previous order and the default is only
+// dropped when it was not allowed before. An empty result removes the
+// constraint, like when there was no constraint before.
+func restorePreviousPlans(values, previousPlans []string, plan string) []string {
+	if len(previousPlans) == 0 {
+		return []string{}
+	}
+
+	current := map[string]bool{}
+	for _, value := range values {
+		current[value] = true
+	}
+
+	result := []string{}
+	previous := map[string]bool{}
+	for _, value := range previousPlans {
+		previous[value] = true
+		if current[value] {
+			result = append(result, value)
+		}
+	}
+
+	for _, value := range values {
+		if !previous[value] && value != plan {
+			result = append(result, value)
+		}
+	}
+
+	return result
+}
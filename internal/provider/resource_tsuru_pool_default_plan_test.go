@@ -0,0 +1,207 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
+)
+
+func testPoolConstraintsServer(t *testing.T, constraints []tsuru.PoolConstraint) (*echo.Echo, func() []tsuru.PoolConstraint) {
+	var mu sync.Mutex
+
+	fakeServer := echo.New()
+	fakeServer.PUT("/1.3/constraints", func(c echo.Context) error {
+		p := &tsuru.PoolConstraintSet{}
+		err := c.Bind(p)
+		require.NoError(t, err)
+
+		mu.Lock()
+		defer mu.Unlock()
+		for i := range constraints {
+			if constraints[i].PoolExpr == p.PoolExpr && constraints[i].Field == p.Field {
+				constraints[i].Values = p.Values
+				constraints[i].Blacklist = p.Blacklist
+				return nil
+			}
+		}
+		constraints = append(constraints, tsuru.PoolConstraint{PoolExpr: p.PoolExpr, Field: p.Field, Values: p.Values, Blacklist: p.Blacklist})
+		return nil
+	})
+	fakeServer.GET("/1.3/constraints", func(c echo.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return c.JSON(http.StatusOK, constraints)
+	})
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("method=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+
+	return fakeServer, func() []tsuru.PoolConstraint {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]tsuru.PoolConstraint{}, constraints...)
+	}
+}
+
+func TestAccTsuruPoolDefaultPlan(t *testing.T) {
+	fakeServer, _ := testPoolConstraintsServer(t, []tsuru.PoolConstraint{
+		{PoolExpr: "my-pool", Field: "plan", Values: []string{"c1m1", "c2m4"}},
+	})
+
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	resourceName := "tsuru_pool_default_plan.default"
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      nil,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "tsuru_pool_default_plan" "default" {
+	pool = "my-pool"
+	plan = "c2m4"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccResourceExists(resourceName),
+					resource.TestCheckResourceAttr(resourceName, "pool", "my-pool"),
+					resource.TestCheckResourceAttr(resourceName, "plan", "c2m4"),
+				),
+			},
+		},
+	})
+}
+
+func TestResourceTsuruPoolDefaultPlan(t *testing.T) {
+	fakeServer, constraints := testPoolConstraintsServer(t, []tsuru.PoolConstraint{
+		{PoolExpr: "*", Field: "plan", Values: []string{"c4m8"}},
+		{PoolExpr: "my-pool", Field: "router", Values: []string{"ingress"}},
+		{PoolExpr: "my-pool", Field: "plan", Values: []string{"c1m1", "c2m4"}},
+	})
+
+	server := httptest.NewServer(fakeServer)
+	defer server.Close()
+
+	cfg := tsuru.NewConfiguration()
+	cfg.BasePath = server.URL
+	provider := &tsuruProvider{TsuruClient: tsuru.NewAPIClient(cfg)}
+
+	d := schema.TestResourceDataRaw(t, resourceTsuruPoolDefaultPlan().Schema, map[string]interface{}{
+		"pool": "my-pool",
+		"plan": "c2m4",
+	})
+
+	diags := resourceTsuruPoolDefaultPlanSet(context.Background(), d, provider)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "my-pool", d.Id())
+	assert.Equal(t, "c2m4", d.Get("plan"))
+	assert.Equal(t, []string{"c2m4", "c1m1"}, constraints()[2].Values)
+	assert.Equal(t, []string{"c4m8"}, constraints()[0].Values)
+	assert.Equal(t, []interface{}{"c1m1", "c2m4"}, d.Get("previous_plans"))
+
+	diags = resourceTsuruPoolDefaultPlanDelete(context.Background(), d, provider)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, []string{"c1m1", "c2m4"}, constraints()[2].Values)
+
+	diags = resourceTsuruPoolDefaultPlanRead(context.Background(), d, provider)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "c1m1", d.Get("plan"))
+}
+
+func TestResourceTsuruPoolDefaultPlanCreatedConstraint(t *testing.T) {
+	fakeServer, constraints := testPoolConstraintsServer(t, []tsuru.PoolConstraint{})
+
+	server := httptest.NewServer(fakeServer)
+	defer server.Close()
+
+	cfg := tsuru.NewConfiguration()
+	cfg.BasePath = server.URL
+	provider := &tsuruProvider{TsuruClient: tsuru.NewAPIClient(cfg)}
+
+	d := schema.TestResourceDataRaw(t, resourceTsuruPoolDefaultPlan().Schema, map[string]interface{}{
+		"pool": "my-pool",
+		"plan": "c2m4",
+	})
+
+	diags := resourceTsuruPoolDefaultPlanSet(context.Background(), d, provider)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, []string{"c2m4"}, constraints()[0].Values)
+	assert.Equal(t, []interface{}{}, d.Get("previous_plans"))
+
+	diags = resourceTsuruPoolDefaultPlanDelete(context.Background(), d, provider)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Empty(t, constraints()[0].Values)
+}
+
+func TestResourceTsuruPoolDefaultPlanImport(t *testing.T) {
+	fakeServer, _ := testPoolConstraintsServer(t, []tsuru.PoolConstraint{
+		{PoolExpr: "my-pool", Field: "plan", Values: []string{"c2m4", "c1m1"}},
+	})
+
+	server := httptest.NewServer(fakeServer)
+	defer server.Close()
+
+	cfg := tsuru.NewConfiguration()
+	cfg.BasePath = server.URL
+	provider := &tsuruProvider{TsuruClient: tsuru.NewAPIClient(cfg)}
+
+	d := resourceTsuruPoolDefaultPlan().Data(nil)
+	d.SetId("my-pool")
+
+	_, err := resourceTsuruPoolDefaultPlanImport(context.Background(), d, provider)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"c2m4", "c1m1"}, d.Get("previous_plans"))
+}
+
+func TestResourceTsuruPoolDefaultPlanBlacklist(t *testing.T) {
+	fakeServer, _ := testPoolConstraintsServer(t, []tsuru.PoolConstraint{
+		{PoolExpr: "my-pool", Field: "plan", Values: []string{"c1m1"}, Blacklist: true},
+	})
+
+	server := httptest.NewServer(fakeServer)
+	defer server.Close()
+
+	cfg := tsuru.NewConfiguration()
+	cfg.BasePath = server.URL
+	provider := &tsuruProvider{TsuruClient: tsuru.NewAPIClient(cfg)}
+
+	d := schema.TestResourceDataRaw(t, resourceTsuruPoolDefaultPlan().Schema, map[string]interface{}{
+		"pool": "my-pool",
+		"plan": "c2m4",
+	})
+
+	diags := resourceTsuruPoolDefaultPlanSet(context.Background(), d, provider)
+	require.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, "denies plans with a blacklist constraint")
+}
+
+func TestDefaultPlanFirst(t *testing.T) {
+	assert.Equal(t, []string{"c2m4"}, defaultPlanFirst(nil, "c2m4"))
+	assert.Equal(t, []string{"c2m4", "c1m1", "c4m8"}, defaultPlanFirst([]string{"c1m1", "c2m4", "c4m8"}, "c2m4"))
+}
+
+func TestRestorePreviousPlans(t *testing.T) {
+	assert.Equal(t, []string{}, restorePreviousPlans([]string{"c2m4"}, nil, "c2m4"))
+	assert.Equal(t, []string{"c1m1", "c2m4"}, restorePreviousPlans([]string{"c2m4", "c1m1"}, []string{"c1m1", "c2m4"}, "c2m4"))
+	assert.Equal(t, []string{"c1m1"}, restorePreviousPlans([]string{"c2m4", "c1m1"}, []string{"c1m1"}, "c2m4"))
+
+	// the previous default set by the resource stays allowed, plans removed
+	// meanwhile are not restored
+	assert.Equal(t, []string{"c1m1", "c4m8"}, restorePreviousPlans([]string{"c2m4", "c4m8", "c1m1"}, []string{"c1m1", "c8m16"}, "c2m4"))
+}
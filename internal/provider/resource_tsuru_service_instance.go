@@ -12,9 +12,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/antihax/optional"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
 	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
 )
 
@@ -24,6 +26,7 @@ func resourceTsuruServiceInstance() *schema.Resource {
 		ReadContext:   resourceTsuruServiceInstanceRead,
 		UpdateContext: resourceTsuruServiceInstanceUpdate,
 		DeleteContext: resourceTsuruServiceInstanceDelete,
+		CustomizeDiff: resourceTsuruServiceInstanceCustomizeDiff,
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(60 * time.Minute),
 			Update: schema.DefaultTimeout(60 * time.Minute),
@@ -49,7 +52,7 @@ func resourceTsuruServiceInstance() *schema.Resource {
 			"plan": {
 				Type:     schema.TypeString,
 				Optional: true,
-				Description: "Service plan name",
+				Description: "Service plan name, validated against the plans available for the service",
 			},
 			"owner": {
 				Type:        schema.TypeString,
@@ -97,6 +100,17 @@ func resourceTsuruServiceInstance() *schema.Resource {
 				Optional:    true,
 				Description: "Wait for instance to reach up state",
 			},
+			"provisioning_status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Provisioning status of the instance reported by the service: `in_progress`, `ready` or `failed`",
+			},
+			"wait_for_ready": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Wait for the service to finish provisioning the instance, failing when the service reports the provisioning as failed",
+			},
+			"retry": retrySchema("Overrides the provider retry settings for the operations of this service instance"),
 		},
 	}
 }
@@ -130,7 +144,10 @@ func resourceTsuruServiceInstanceCreate(ctx context.Context, d *schema.ResourceD
 		instance.Parameters = parseParameters(parameters)
 	}
 
-	_, err := provider.TsuruClient.ServiceApi.InstanceCreate(ctx, serviceName, instance)
+	err := tsuruRetry(ctx, provider, d, func() error {
+		_, err := provider.TsuruClient.ServiceApi.InstanceCreate(ctx, serviceName, instance)
+		return err
+	})
 
 	if err != nil {
 		return diag.Errorf("Could not create tsuru service instance, err : %s", err.Error())
@@ -150,6 +167,16 @@ func resourceTsuruServiceInstanceCreate(ctx context.Context, d *schema.ResourceD
 		}
 	}
 
+	if d.Get("wait_for_ready").(bool) {
+		log.Printf("[INFO] Waiting for service_instance %s/%s to be ready", serviceName, name)
+		err := resource.RetryContext(ctx, d.Timeout(schema.TimeoutCreate),
+			waitForServiceInstanceReadyFunc(ctx, provider, serviceName, name),
+		)
+		if err != nil {
+			return diag.Errorf("service instance %s/%s is not ready: %v", serviceName, name, err)
+		}
+	}
+
 	return resourceTsuruServiceInstanceRead(ctx, d, meta)
 }
 
@@ -190,12 +217,21 @@ func resourceTsuruServiceInstanceRead(ctx context.Context, d *schema.ResourceDat
 		d.Set("parameters", instance.Parameters)
 	}
 
+	// services report failed provisionings as errors on status, the instance
+	// still exists and must remain readable to be fixed or destroyed
 	status, err := serviceInstanceStatus(ctx, provider, serviceName, name)
 	if err != nil {
+		if _, ok := serviceInstanceProvisioningError(err); ok {
+			log.Printf("[WARN] Could not read tsuru service (%s) instance (%s) status, err : %s", serviceName, name, err.Error())
+			d.Set("status", "")
+			d.Set("provisioning_status", serviceInstanceFailed)
+			return nil
+		}
 		return diag.Errorf("Could not read tsuru service (%s) instance (%s) status, err : %s", serviceName, name, err.Error())
 	}
 
 	d.Set("status", status)
+	d.Set("provisioning_status", serviceInstanceProvisioningStatus(status))
 
 	return nil
 
@@ -223,7 +259,10 @@ func resourceTsuruServiceInstanceUpdate(ctx context.Context, d *schema.ResourceD
 		instanceData.Parameters = parseParameters(parameters)
 	}
 
-	_, err := provider.TsuruClient.ServiceApi.InstanceUpdate(ctx, serviceName, name, instanceData)
+	err := tsuruRetry(ctx, provider, d, func() error {
+		_, err := provider.TsuruClient.ServiceApi.InstanceUpdate(ctx, serviceName, name, instanceData)
+		return err
+	})
 	if err != nil {
 		return diag.Errorf("Could not update tsuru service instance: %q, err: %s", d.Id(), err.Error())
 	}
@@ -240,6 +279,16 @@ func resourceTsuruServiceInstanceUpdate(ctx context.Context, d *schema.ResourceD
 		}
 	}
 
+	if d.Get("wait_for_ready").(bool) {
+		log.Printf("[INFO] Waiting for service_instance %s/%s to be ready", serviceName, name)
+		err := resource.RetryContext(ctx, d.Timeout(schema.TimeoutUpdate),
+			waitForServiceInstanceReadyFunc(ctx, provider, serviceName, name),
+		)
+		if err != nil {
+			return diag.Errorf("service instance %s/%s is not ready: %v", serviceName, name, err)
+		}
+	}
+
 	return resourceTsuruServiceInstanceRead(ctx, d, meta)
 }
 
@@ -249,7 +298,10 @@ func resourceTsuruServiceInstanceDelete(ctx context.Context, d *schema.ResourceD
 	serviceName := d.Get("service_name").(string)
 	unbind := d.Get("unbind_on_delete").(bool)
 
-	_, err := provider.TsuruClient.ServiceApi.InstanceDelete(ctx, serviceName, name, unbind)
+	err := tsuruRetry(ctx, provider, d, func() error {
+		_, err := provider.TsuruClient.ServiceApi.InstanceDelete(ctx, serviceName, name, unbind)
+		return err
+	})
 	if err != nil {
 		return diag.Errorf("Could not delete tsuru service instance, err: %s", err.Error())
 	}
@@ -257,6 +309,49 @@ func resourceTsuruServiceInstanceDelete(ctx context.Context, d *schema.ResourceD
 	return nil
 }
 
+func resourceTsuruServiceInstanceCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.HasChange("plan") {
+		return nil
+	}
+
+	// values that depend on other resources are only known at apply time
+	if !d.NewValueKnown("plan") || !d.NewValueKnown("service_name") || !d.NewValueKnown("pool") {
+		return nil
+	}
+
+	plan := d.Get("plan").(string)
+	if plan == "" {
+		return nil
+	}
+
+	provider := meta.(*tsuruProvider)
+	return validServicePlan(ctx, provider, d.Get("service_name").(string), d.Get("pool").(string), plan)
+}
+
+func validServicePlan(ctx context.Context, provider *tsuruProvider, serviceName, pool, plan string) error {
+	opts := &tsuru.ServicePlansOpts{}
+	if pool != "" {
+		opts.Pool = optional.NewString(pool)
+	}
+
+	plans, _, err := provider.TsuruClient.ServiceApi.ServicePlans(ctx, serviceName, opts)
+	if err != nil {
+		log.Printf("[WARN] unable to fetch plans of service %s, skipping validation of plan %s: %v", serviceName, plan, err)
+		return nil
+	}
+
+	availablePlans := []string{}
+	for _, p := range plans {
+		availablePlans = append(availablePlans, p.Name)
+		if p.Name == plan {
+			return nil
+		}
+	}
+	plansList := strings.Join(availablePlans, ",")
+
+	return errors.Errorf("invalid plan: %s for service %s, available plans are [%s]", plan, serviceName, plansList)
+}
+
 func serviceInstanceStatus(ctx context.Context, provider *tsuruProvider, serviceName, serviceInstance string) (string, error) {
 	response, err := provider.TsuruClient.ServiceApi.ServiceInstanceStatus(ctx, serviceName, serviceInstance)
 	if err != nil {
@@ -288,6 +383,73 @@ func waitForServiceInstanceStatusUpFunc(ctx context.Context, provider *tsuruProv
 		return resource.RetryableError(fmt.Errorf("current status %q", currentStatus))
 	}
 }
+
+func waitForServiceInstanceReadyFunc(ctx context.Context, provider *tsuruProvider, serviceName, serviceInstance string) resource.RetryFunc {
+	return func() *resource.RetryError {
+		currentStatus, err := serviceInstanceStatus(ctx, provider, serviceName, serviceInstance)
+		if err != nil {
+			if message, ok := serviceInstanceProvisioningError(err); ok {
+				return resource.NonRetryableError(fmt.Errorf("provisioning failed: %s", message))
+			}
+			return resource.NonRetryableError(err)
+		}
+
+		switch serviceInstanceProvisioningStatus(currentStatus) {
+		case serviceInstanceReady:
+			log.Printf("[INFO] service %s/%s is ready", serviceName, serviceInstance)
+			return nil
+		case serviceInstanceFailed:
+			return resource.NonRetryableError(fmt.Errorf("provisioning failed, current status %q", currentStatus))
+		}
+
+		log.Printf("[INFO] service %s/%s current status %q ", serviceName, serviceInstance, currentStatus)
+		return resource.RetryableError(fmt.Errorf("current status %q", currentStatus))
+	}
+}
+
+const (
+	serviceInstanceInProgress = "in_progress"
+	serviceInstanceReady      = "ready"
+	serviceInstanceFailed     = "failed"
+)
+
+// serviceInstanceProvisioningStatus maps the status reported by tsuru, like
+// `Service instance "name" is pending`, to the provisioning status. tsuru
+// reports "pending" when the service answers 202 and "down" when it answers
+// 500, any other answer, like the body of a 200, means the instance is ready.
+func serviceInstanceProvisioningStatus(status string) string {
+	state := status
+	if i := strings.Index(status, `" is `); i >= 0 {
+		state = status[i+len(`" is `):]
+	}
+
+	switch strings.TrimSpace(state) {
+	case "pending":
+		return serviceInstanceInProgress
+	case "down":
+		return serviceInstanceFailed
+	}
+
+	return serviceInstanceReady
+}
+
+// serviceInstanceProvisioningError returns the message of status errors
+// reported by the service itself, other errors, like network or
+// authorization ones, are not about the provisioning.
+func serviceInstanceProvisioningError(err error) (string, bool) {
+	var apiError tsuru.GenericOpenAPIError
+	if !errors.As(err, &apiError) {
+		return "", false
+	}
+
+	message := strings.TrimSpace(string(apiError.Body()))
+	if !strings.Contains(message, "Failed to get status of instance") {
+		return "", false
+	}
+
+	return message, true
+}
+
 func parseTags(data interface{}) []string {
 	values := []string{}
 
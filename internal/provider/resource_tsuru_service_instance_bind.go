@@ -31,7 +31,7 @@ func resourceTsuruServiceInstanceBind() *schema.Resource {
 			Delete: schema.DefaultTimeout(60 * time.Minute),
 		},
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceTsuruServiceInstanceBindImport,
 		},
 		Schema: map[string]*schema.Schema{
 			"service_name": {
@@ -140,7 +140,8 @@ func resourceTsuruServiceInstanceBindRead(ctx context.Context, d *schema.Resourc
 	service := parts[0]
 	instanceName := parts[1]
 	name := parts[2]
-	if len(parts) == 4 {
+	isJob := len(parts) == 4
+	if isJob {
 		name = parts[3]
 	}
 
@@ -153,8 +154,9 @@ func resourceTsuruServiceInstanceBindRead(ctx context.Context, d *schema.Resourc
 		return diag.Errorf("unable to read bind %s %s: %v", service, instanceName, err)
 	}
 
+	// apps and jobs may share names, only look at the kind of the bind
 	for _, a := range instance.Apps {
-		if name == a {
+		if !isJob && name == a {
 			d.Set("app", a)
 			d.Set("service_name", service)
 			d.Set("service_instance", instanceName)
@@ -163,7 +165,7 @@ func resourceTsuruServiceInstanceBindRead(ctx context.Context, d *schema.Resourc
 	}
 
 	for _, j := range instance.Jobs {
-		if name == j {
+		if isJob && name == j {
 			d.Set("job", j)
 			d.Set("service_name", service)
 			d.Set("service_instance", instanceName)
@@ -175,6 +177,24 @@ func resourceTsuruServiceInstanceBindRead(ctx context.Context, d *schema.Resourc
 	return nil
 }
 
+// resourceTsuruServiceInstanceBindImport accepts IDs formatted as
+// service::instance::app or service::instance::tsuru-job::job.
+func resourceTsuruServiceInstanceBindImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	parts, err := IDtoParts(d.Id(), 3)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) > 4 || (len(parts) == 4 && parts[2] != "tsuru-job") {
+		return nil, errors.Errorf("invalid bind ID %q, expected service::instance::app or service::instance::tsuru-job::job", d.Id())
+	}
+
+	// restart_on_update is not stored by tsuru, use its default to avoid a
+	// diff right after the import
+	d.Set("restart_on_update", true)
+
+	return []*schema.ResourceData{d}, nil
+}
+
 func resourceTsuruServiceInstanceBindDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	provider := meta.(*tsuruProvider)
 
@@ -5,6 +5,7 @@
 package provider
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -12,8 +13,10 @@ import (
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	echo "github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
 )
 
@@ -86,6 +89,19 @@ func TestAccResourceServiceInstanceBind(t *testing.T) {
 					resource.TestCheckResourceAttr(resourceName, "restart_on_update", "false"),
 				),
 			},
+			{
+				ResourceName:            resourceName,
+				ImportState:             true,
+				ImportStateId:           "service01::my-instance::app01",
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"restart_on_update"},
+			},
+			{
+				ResourceName:  resourceName,
+				ImportState:   true,
+				ImportStateId: "service01::my-instance::app01::extra",
+				ExpectError:   regexp.MustCompile("invalid bind ID"),
+			},
 		},
 	})
 }
@@ -102,7 +118,8 @@ func TestAccResourceServiceInstanceJobBind(t *testing.T) {
 		}
 
 		instance := &tsuru.ServiceInstanceInfo{
-			Apps:      []string{},
+			// an app with the same name of the job must not be mistaken by the job bind
+			Apps:      []string{"job01"},
 			Jobs:      []string{},
 			Teamowner: "my-team",
 			Teams:     []string{},
@@ -202,3 +219,23 @@ func testAccResourceServiceInstanceJobBind_basic() string {
 	}
 `
 }
+
+func TestResourceTsuruServiceInstanceBindImport(t *testing.T) {
+	for _, id := range []string{"service01::my-instance::app01", "service01::my-instance::tsuru-job::job01"} {
+		d := schema.TestResourceDataRaw(t, resourceTsuruServiceInstanceBind().Schema, map[string]interface{}{})
+		d.SetId(id)
+
+		result, err := resourceTsuruServiceInstanceBindImport(context.Background(), d, nil)
+		require.NoError(t, err, id)
+		require.Len(t, result, 1)
+		assert.Equal(t, true, result[0].Get("restart_on_update"), id)
+	}
+
+	for _, id := range []string{"service01::my-instance", "service01::my-instance::app01::job01", "a::b::tsuru-job::c::d"} {
+		d := schema.TestResourceDataRaw(t, resourceTsuruServiceInstanceBind().Schema, map[string]interface{}{})
+		d.SetId(id)
+
+		_, err := resourceTsuruServiceInstanceBindImport(context.Background(), d, nil)
+		assert.Error(t, err, id)
+	}
+}
@@ -5,10 +5,12 @@
 package provider
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -22,6 +24,10 @@ func TestTsuruServiceInstance_basic(t *testing.T) {
 	fakeServer := echo.New()
 	interationStatus := 0
 
+	fakeServer.GET("/1.0/services/rpaasv2/plans", func(c echo.Context) error {
+		assert.Equal(t, "some-pool", c.QueryParam("pool"))
+		return c.JSON(http.StatusOK, []tsuru.ServicePlan{{Name: "c1m1"}, {Name: "c2m2"}})
+	})
 	fakeServer.POST("/1.0/services/rpaasv2/instances", func(c echo.Context) error {
 		si := &tsuru.ServiceInstance{}
 		err := c.Bind(&si)
@@ -80,12 +86,144 @@ func TestTsuruServiceInstance_basic(t *testing.T) {
 					testAccResourceExists(resourceName),
 					resource.TestCheckResourceAttr(resourceName, "name", "my-reverse-proxy"),
 					resource.TestCheckResourceAttr(resourceName, "service_name", "rpaasv2"),
+					resource.TestCheckResourceAttr(resourceName, "provisioning_status", "ready"),
 				),
 			},
 		},
 	})
 }
 
+func TestTsuruServiceInstance_invalidPlan(t *testing.T) {
+	fakeServer := echo.New()
+
+	fakeServer.GET("/1.0/services/rpaasv2/plans", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, []tsuru.ServicePlan{{Name: "c1m1"}, {Name: "c4m4"}})
+	})
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      nil,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccTsuruServiceInstanceConfig_basic(server.URL, "my-reverse-proxy"),
+				ExpectError: regexp.MustCompile(`invalid plan: c2m2 for service rpaasv2, available plans are \[c1m1,c4m4\]`),
+			},
+		},
+	})
+}
+
+func TestValidServicePlanUnavailablePlans(t *testing.T) {
+	fakeServer := echo.New()
+	fakeServer.GET("/1.0/services/rpaasv2/plans", func(c echo.Context) error {
+		return c.String(http.StatusInternalServerError, "service unavailable")
+	})
+	server := httptest.NewServer(fakeServer)
+	defer server.Close()
+
+	cfg := tsuru.NewConfiguration()
+	cfg.BasePath = server.URL
+	provider := &tsuruProvider{TsuruClient: tsuru.NewAPIClient(cfg)}
+
+	// plans that can not be listed, like without permission, are not validated
+	err := validServicePlan(context.Background(), provider, "rpaasv2", "", "c2m2")
+	assert.NoError(t, err)
+}
+
+func TestTsuruServiceInstance_provisioningFailed(t *testing.T) {
+	fakeServer := echo.New()
+
+	fakeServer.POST("/1.0/services/mysql/instances", func(c echo.Context) error {
+		return nil
+	})
+	fakeServer.GET("/1.0/services/mysql/instances/my-db", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, tsuru.ServiceInstanceInfo{
+			Teamowner: "my-team",
+		})
+	})
+	iterationStatus := 0
+	fakeServer.GET("/1.0/services/mysql/instances/my-db/status", func(c echo.Context) error {
+		iterationStatus++
+		if iterationStatus < 2 {
+			return c.String(http.StatusOK, `Service instance "my-db" is pending`)
+		}
+		return c.String(http.StatusInternalServerError, "Could not retrieve status of service instance, error: Failed to get status of instance my-db: quota of databases exceeded")
+	})
+	fakeServer.DELETE("/1.0/services/mysql/instances/my-db", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      nil,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "tsuru_service_instance" "my_db" {
+	service_name   = "mysql"
+	name           = "my-db"
+	owner          = "my-team"
+	wait_for_ready = true
+}
+`,
+				ExpectError: regexp.MustCompile(`service instance mysql/my-db is not ready: provisioning failed: .*quota of databases exceeded`),
+			},
+		},
+	})
+}
+
+func TestResourceTsuruServiceInstanceReadStatusErrors(t *testing.T) {
+	statusCode, statusBody := 0, ""
+
+	fakeServer := echo.New()
+	fakeServer.GET("/1.0/services/mysql/instances/my-db", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, tsuru.ServiceInstanceInfo{Teamowner: "my-team"})
+	})
+	fakeServer.GET("/1.0/services/mysql/instances/my-db/status", func(c echo.Context) error {
+		return c.String(statusCode, statusBody)
+	})
+	server := httptest.NewServer(fakeServer)
+	defer server.Close()
+
+	cfg := tsuru.NewConfiguration()
+	cfg.BasePath = server.URL
+	provider := &tsuruProvider{TsuruClient: tsuru.NewAPIClient(cfg)}
+
+	statusCode, statusBody = http.StatusInternalServerError, "Could not retrieve status of service instance, error: Failed to get status of instance my-db: quota of databases exceeded"
+	d := resourceTsuruServiceInstance().Data(nil)
+	d.SetId("mysql::my-db")
+	diags := resourceTsuruServiceInstanceRead(context.Background(), d, provider)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "failed", d.Get("provisioning_status"))
+
+	statusCode, statusBody = http.StatusForbidden, "You don't have permission to do this action"
+	d = resourceTsuruServiceInstance().Data(nil)
+	d.SetId("mysql::my-db")
+	diags = resourceTsuruServiceInstanceRead(context.Background(), d, provider)
+	require.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, "Could not read tsuru service (mysql) instance (my-db) status")
+}
+
+func TestServiceInstanceProvisioningStatus(t *testing.T) {
+	assert.Equal(t, "in_progress", serviceInstanceProvisioningStatus(`Service instance "my-db" is pending`))
+	assert.Equal(t, "ready", serviceInstanceProvisioningStatus(`Service instance "my-db" is up`))
+	assert.Equal(t, "ready", serviceInstanceProvisioningStatus(`Service instance "my-db" is not implemented for this service`))
+	assert.Equal(t, "failed", serviceInstanceProvisioningStatus(`Service instance "my-db" is down`))
+	assert.Equal(t, "ready", serviceInstanceProvisioningStatus(`Service instance "my-db" is running`))
+	assert.Equal(t, "ready", serviceInstanceProvisioningStatus(`Service instance "my-db" is {"status": "ok", "replicas": "3 is enough"}`))
+}
+
 func testAccTsuruServiceInstanceConfig_basic(fakeServer, name string) string {
 	return fmt.Sprintf(`
 resource "tsuru_service_instance"  "my_reverse_proxy"   {
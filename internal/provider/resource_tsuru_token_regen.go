@@ -0,0 +1,119 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+	tsuru_client "github.com/tsuru/go-tsuruclient/pkg/tsuru"
+)
+
+func resourceTsuruTokenRegen() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Regenerate the value of an existing tsuru team token, a new value is generated every time any of the triggers change",
+		CreateContext: resourceTsuruTokenRegenCreate,
+		ReadContext:   resourceTsuruTokenRegenRead,
+		DeleteContext: resourceTsuruTokenRegenDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(60 * time.Minute),
+			Delete: schema.DefaultTimeout(60 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"token_id": {
+				Type:        schema.TypeString,
+				Description: "Identifier of the token to be regenerated",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Description: "Arbitrary map of values that, when changed, will regenerate the token",
+				Optional:    true,
+				ForceNew:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"token": {
+				Type:        schema.TypeString,
+				Description: "Regenerated tsuru token",
+				Computed:    true,
+				Sensitive:   true,
+			},
+			"regenerated_at": {
+				Type:        schema.TypeString,
+				Description: "Date of the last regeneration made by this resource",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceTsuruTokenRegenCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	provider := meta.(*tsuruProvider)
+	tokenId := d.Get("token_id").(string)
+
+	var teamToken tsuru_client.TeamToken
+	err := resource.RetryContext(ctx, d.Timeout(schema.TimeoutCreate), func() *resource.RetryError {
+		var err error
+		teamToken, _, err = provider.TsuruClient.AuthApi.TeamTokenUpdate(ctx, tokenId, tsuru_client.TeamTokenUpdateArgs{
+			Regenerate: true,
+		})
+		if err != nil {
+			var apiError tsuru_client.GenericOpenAPIError
+			if errors.As(err, &apiError) {
+				if isRetryableError(apiError.Body()) {
+					return resource.RetryableError(err)
+				}
+			}
+			return resource.NonRetryableError(err)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return diag.Errorf("unable to regenerate token %s: %v", tokenId, err)
+	}
+
+	d.SetId(tokenId)
+	d.Set("token", teamToken.Token)
+	d.Set("regenerated_at", formatDate(time.Now()))
+
+	return resourceTsuruTokenRegenRead(ctx, d, meta)
+}
+
+func resourceTsuruTokenRegenRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	provider := meta.(*tsuruProvider)
+	tokenId := d.Id()
+
+	teamToken, _, err := provider.TsuruClient.AuthApi.TeamTokenInfo(ctx, tokenId)
+	if err != nil {
+		if isNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.Errorf("unable to read token %s: %v", tokenId, err)
+	}
+
+	d.Set("token_id", tokenId)
+
+	// the token value is only returned when the user is allowed to see it
+	if teamToken.Token != "" {
+		d.Set("token", teamToken.Token)
+	}
+
+	return nil
+}
+
+func resourceTsuruTokenRegenDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Println("[DEBUG] delete a token regeneration is a no-op by terraform")
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tsuru/go-tsuruclient/pkg/tsuru"
+)
+
+func TestAccResourceTsuruTokenRegen(t *testing.T) {
+	fakeServer := echo.New()
+
+	regenerateCount := 0
+	currentToken := "initial-token"
+
+	fakeServer.PUT("/1.6/tokens/:token", func(c echo.Context) error {
+		args := tsuru.TeamTokenUpdateArgs{}
+		err := c.Bind(&args)
+		require.NoError(t, err)
+		assert.Equal(t, "my-simple-token", c.Param("token"))
+		assert.True(t, args.Regenerate)
+		assert.Equal(t, "", args.Description)
+		assert.Equal(t, int64(0), args.ExpiresIn)
+
+		regenerateCount++
+		if regenerateCount == 1 {
+			currentToken = "regenerated-token-1"
+		} else {
+			currentToken = "regenerated-token-2"
+		}
+
+		return c.JSON(http.StatusOK, tsuru.TeamToken{
+			TokenId: "my-simple-token",
+			Token:   currentToken,
+			Team:    "team-dev",
+		})
+	})
+
+	fakeServer.GET("/1.7/tokens/:token", func(c echo.Context) error {
+		if c.Param("token") != "my-simple-token" {
+			return c.JSON(http.StatusNotFound, nil)
+		}
+		return c.JSON(http.StatusOK, tsuru.TeamToken{
+			TokenId: "my-simple-token",
+			Token:   currentToken,
+			Team:    "team-dev",
+		})
+	})
+
+	fakeServer.HTTPErrorHandler = func(err error, c echo.Context) {
+		t.Errorf("methods=%s, path=%s, err=%s", c.Request().Method, c.Path(), err.Error())
+	}
+	server := httptest.NewServer(fakeServer)
+	os.Setenv("TSURU_TARGET", server.URL)
+
+	resourceName := "tsuru_token_regen.rotation"
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      nil,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceTsuruTokenRegen_basic("2024-01"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccResourceExists(resourceName),
+					resource.TestCheckResourceAttr(resourceName, "token_id", "my-simple-token"),
+					resource.TestCheckResourceAttr(resourceName, "token", "regenerated-token-1"),
+				),
+			},
+			{
+				Config: testAccResourceTsuruTokenRegen_basic("2024-02"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccResourceExists(resourceName),
+					resource.TestCheckResourceAttr(resourceName, "token_id", "my-simple-token"),
+					resource.TestCheckResourceAttr(resourceName, "token", "regenerated-token-2"),
+				),
+			},
+		},
+	})
+
+	assert.Equal(t, 2, regenerateCount)
+}
+
+func testAccResourceTsuruTokenRegen_basic(rotation string) string {
+	return `
+	resource "tsuru_token_regen" "rotation" {
+		token_id = "my-simple-token"
+		triggers = {
+			rotation = "` + rotation + `"
+		}
+	}
+`
+}
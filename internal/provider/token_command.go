@@ -0,0 +1,128 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// tokenCommand obtains tokens from an external command, like an auth broker,
+// running it again when tsuru API refuses the current token. Tokens must
+// never be logged.
+type tokenCommand struct {
+	command string
+
+	mu    sync.Mutex
+	token string
+}
+
+func newTokenCommand(command string) *tokenCommand {
+	return &tokenCommand{command: command}
+}
+
+// Token returns the current token, running the command on the first call.
+func (c *tokenCommand) Token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" {
+		return c.token, nil
+	}
+	return c.run(ctx)
+}
+
+// Refresh runs the command again unless the stale token was already replaced
+// by a concurrent refresh.
+func (c *tokenCommand) Refresh(ctx context.Context, stale string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && c.token != stale {
+		return c.token, nil
+	}
+	return c.run(ctx)
+}
+
+func (c *tokenCommand) run(ctx context.Context) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", c.command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	// stdout is left out of errors, it may hold part of a token
+	if err := cmd.Run(); err != nil {
+		return "", errors.Errorf("token_command failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	token := strings.TrimSpace(stdout.String())
+	if token == "" {
+		return "", errors.New("token_command returned an empty token")
+	}
+
+	c.token = token
+	return token, nil
+}
+
+func (c *tokenCommand) wrap(base http.RoundTripper) http.RoundTripper {
+	return &tokenCommandTransport{base: base, command: c}
+}
+
+type tokenCommandTransport struct {
+	base    http.RoundTripper
+	command *tokenCommand
+}
+
+func (t *tokenCommandTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.command.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(withAuthorization(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	// only requests with a body that can be sent again are retried
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+
+	newToken, err := t.command.Refresh(req.Context(), token)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if newToken == token {
+		return resp, nil
+	}
+
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	retry := withAuthorization(req, newToken)
+	if req.GetBody != nil {
+		retry.Body, err = req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return t.base.RoundTrip(retry)
+}
+
+func withAuthorization(req *http.Request, token string) *http.Request {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", token)
+	return req
+}
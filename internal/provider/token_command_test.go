@@ -0,0 +1,100 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCountingTokenCommand returns a command printing token-N, N being the
+// number of times it ran, and a function reading N.
+func testCountingTokenCommand(t *testing.T) (string, func() string) {
+	counter := filepath.Join(t.TempDir(), "counter")
+	command := fmt.Sprintf(`n=$(cat %[1]q 2>/dev/null || echo 0); n=$((n+1)); echo $n > %[1]q; echo "  token-$n  "`, counter)
+	return command, func() string {
+		data, _ := os.ReadFile(counter)
+		return strings.TrimSpace(string(data))
+	}
+}
+
+func TestTokenCommand(t *testing.T) {
+	command, runs := testCountingTokenCommand(t)
+	tc := newTokenCommand(command)
+
+	token, err := tc.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+
+	token, err = tc.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+	assert.Equal(t, "1", runs())
+
+	token, err = tc.Refresh(context.Background(), "token-1")
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", token)
+
+	// a stale token already replaced is not refreshed again
+	token, err = tc.Refresh(context.Background(), "token-1")
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", token)
+	assert.Equal(t, "2", runs())
+}
+
+func TestTokenCommandErrors(t *testing.T) {
+	_, err := newTokenCommand("echo my-secret-token; echo broker unavailable >&2; exit 3").Token(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "token_command failed: exit status 3: broker unavailable")
+	assert.NotContains(t, err.Error(), "my-secret-token")
+
+	_, err = newTokenCommand("echo '   '").Token(context.Background())
+	assert.EqualError(t, err, "token_command returned an empty token")
+}
+
+func TestTokenCommandTransportRefreshOnUnauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	command, runs := testCountingTokenCommand(t)
+	client := newHTTPClient(transportSettings{TokenCommand: newTokenCommand(command)})
+
+	req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("payload"))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "static-token")
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "payload", string(body))
+	assert.Equal(t, "2", runs())
+
+	resp, err = client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "2", runs())
+}
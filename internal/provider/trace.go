@@ -0,0 +1,217 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const traceRedacted = "<redacted>"
+
+var (
+	traceRedactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+	// fields of JSON and form bodies holding credentials, private env values
+	// are redacted as well
+	traceRedactedFields = map[string]bool{
+		"token":           true,
+		"key":             true,
+		"clientkey":       true,
+		"client-key-data": true,
+	}
+)
+
+// httpTracer writes the requests sent to tsuru API and their responses to a
+// file, with credentials redacted.
+type httpTracer struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func newHTTPTracer(path string) (*httpTracer, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to open trace file %s", path)
+	}
+	return &httpTracer{out: file}, nil
+}
+
+func (t *httpTracer) wrap(base http.RoundTripper) http.RoundTripper {
+	return &tracingTransport{base: base, tracer: t}
+}
+
+func (t *httpTracer) write(data []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.out.Write(data)
+}
+
+type tracingTransport struct {
+	base   http.RoundTripper
+	tracer *httpTracer
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "---- request %s ----\n", time.Now().UTC().Format(time.RFC3339Nano))
+	fmt.Fprintf(&buf, "%s %s %s\nHost: %s\n", req.Method, req.URL.RequestURI(), req.Proto, req.URL.Host)
+	writeTraceHeaders(&buf, req.Header)
+	buf.Write(redactTraceBody(req.Header.Get("Content-Type"), body))
+	buf.WriteString("\n")
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		fmt.Fprintf(&buf, "---- error after %s ----\n%v\n\n", time.Since(start), err)
+		t.tracer.write(buf.Bytes())
+		return nil, err
+	}
+
+	fmt.Fprintf(&buf, "---- response after %s ----\n%s %s\n", time.Since(start), resp.Proto, resp.Status)
+	writeTraceHeaders(&buf, resp.Header)
+
+	contentType := resp.Header.Get("Content-Type")
+	if !isTraceBuffered(contentType) {
+		// streams, like deploy logs, are traced while they are consumed
+		t.tracer.write(buf.Bytes())
+		resp.Body = &traceReadCloser{body: resp.Body, tracer: t.tracer}
+		return resp, nil
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	if err != nil {
+		fmt.Fprintf(&buf, "<unable to read body: %v>", err)
+	}
+	buf.Write(redactTraceBody(contentType, respBody))
+	buf.WriteString("\n\n")
+	t.tracer.write(buf.Bytes())
+
+	return resp, nil
+}
+
+type traceReadCloser struct {
+	body   io.ReadCloser
+	tracer *httpTracer
+}
+
+func (r *traceReadCloser) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	if n > 0 {
+		r.tracer.write(p[:n])
+	}
+	return n, err
+}
+
+func (r *traceReadCloser) Close() error {
+	r.tracer.write([]byte("\n\n"))
+	return r.body.Close()
+}
+
+func writeTraceHeaders(buf *bytes.Buffer, header http.Header) {
+	header = header.Clone()
+	for _, name := range traceRedactedHeaders {
+		if header.Get(name) != "" {
+			header.Set(name, traceRedacted)
+		}
+	}
+	header.Write(buf)
+	buf.WriteString("\n")
+}
+
+func isTraceBuffered(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "application/json" || mediaType == "application/x-www-form-urlencoded"
+}
+
+func redactTraceBody(contentType string, body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/json":
+		var data interface{}
+		if err := json.Unmarshal(body, &data); err != nil {
+			return []byte("<redacted: invalid JSON body>")
+		}
+		redactTraceValue(data)
+
+		var redacted bytes.Buffer
+		encoder := json.NewEncoder(&redacted)
+		encoder.SetEscapeHTML(false)
+		encoder.Encode(data)
+		return bytes.TrimSuffix(redacted.Bytes(), []byte("\n"))
+
+	case "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return []byte("<redacted: invalid form body>")
+		}
+		for name := range values {
+			if isTraceSensitiveField(name) {
+				values.Set(name, traceRedacted)
+			}
+		}
+		return []byte(values.Encode())
+	}
+
+	return body
+}
+
+func redactTraceValue(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		// env vars are sent with private and read back with public
+		private, _ := v["private"].(bool)
+		if public, ok := v["public"].(bool); ok && !public {
+			private = true
+		}
+
+		for name, item := range v {
+			if isTraceSensitiveField(name) || (private && name == "value") {
+				v[name] = traceRedacted
+				continue
+			}
+			redactTraceValue(item)
+		}
+
+	case []interface{}:
+		for _, item := range v {
+			redactTraceValue(item)
+		}
+	}
+}
+
+func isTraceSensitiveField(name string) bool {
+	name = strings.ToLower(name)
+	return traceRedactedFields[name] || strings.Contains(name, "password") || strings.Contains(name, "secret")
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPTracer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/1.0/apps/app01/log" {
+			w.Header().Set("Content-Type", "application/x-json-stream")
+			w.Write([]byte(`{"Message":"deploying"}` + "\n"))
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"envs":[{"name":"DB_PASSWORD","value":"hunter2","private":true},{"name":"PORT","value":"8888"}],"password":"s3cr3t"}`, string(body))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=abc")
+		w.Write([]byte(`{"token":"my-token","token_id":"id-1","envs":[{"name":"DB_PASSWORD","value":"hunter2","public":false}]}`))
+	}))
+	defer server.Close()
+
+	traceFile := filepath.Join(t.TempDir(), "trace.log")
+	tracer, err := newHTTPTracer(traceFile)
+	require.NoError(t, err)
+
+	client := newHTTPClient(transportSettings{Trace: tracer})
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/1.0/apps/app01/env",
+		strings.NewReader(`{"envs":[{"name":"DB_PASSWORD","value":"hunter2","private":true},{"name":"PORT","value":"8888"}],"password":"s3cr3t"}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "bearer my-token")
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Contains(t, string(body), `"token":"my-token"`)
+
+	resp, err = client.Get(server.URL + "/1.0/apps/app01/log")
+	require.NoError(t, err)
+	body, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, `{"Message":"deploying"}`+"\n", string(body))
+
+	trace, err := os.ReadFile(traceFile)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(trace), "POST /1.0/apps/app01/env HTTP/1.1")
+	assert.Contains(t, string(trace), "Authorization: <redacted>")
+	assert.Contains(t, string(trace), "Set-Cookie: <redacted>")
+	assert.Contains(t, string(trace), `"password":"<redacted>"`)
+	assert.Contains(t, string(trace), `"value":"8888"`)
+	assert.Contains(t, string(trace), `"token_id":"id-1"`)
+	assert.Contains(t, string(trace), `{"Message":"deploying"}`)
+	assert.NotContains(t, string(trace), "my-token")
+	assert.NotContains(t, string(trace), "hunter2")
+	assert.NotContains(t, string(trace), "s3cr3t")
+	assert.NotContains(t, string(trace), "session=abc")
+}
+
+func TestRedactTraceBody(t *testing.T) {
+	assert.Equal(t, "image=myrepo%2Fapp01&password=%3Credacted%3E",
+		string(redactTraceBody("application/x-www-form-urlencoded", []byte("image=myrepo%2Fapp01&password=s3cr3t"))))
+	assert.Equal(t, `{"clientkey":"<redacted>","name":"c1"}`,
+		string(redactTraceBody("application/json; charset=utf-8", []byte(`{"name":"c1","clientkey":"a2V5"}`))))
+	assert.Equal(t, "<redacted: invalid JSON body>",
+		string(redactTraceBody("application/json", []byte(`{"token":`))))
+	assert.Equal(t, "plain error", string(redactTraceBody("text/plain", []byte("plain error"))))
+}
@@ -0,0 +1,119 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// unixSocketBasePath is the base path of requests sent through a unix
+// socket, the host is ignored by the dialer.
+const unixSocketBasePath = "http://localhost"
+
+type transportSettings struct {
+	SkipCertVerification bool
+	SocketPath           string
+	KeepAlive            time.Duration
+	DisableHTTP2         bool
+	DisableCompression   bool
+	Trace                *httpTracer
+	TokenCommand         *tokenCommand
+}
+
+// parseTsuruHost returns the base path of tsuru API and the unix socket to
+// dial, if any. Hosts without scheme default to http, like tsuru-client does.
+func parseTsuruHost(host string) (string, string, error) {
+	if strings.HasPrefix(host, "unix://") {
+		socketPath := strings.TrimPrefix(host, "unix://")
+		if !strings.HasPrefix(socketPath, "/") {
+			return "", "", errors.Errorf("invalid host %q: unix socket path must be absolute, like unix:///var/run/tsuru.sock", host)
+		}
+		return unixSocketBasePath, socketPath, nil
+	}
+
+	if !strings.Contains(host, "://") {
+		host = "http://" + host
+	}
+
+	u, err := url.Parse(host)
+	if err != nil {
+		return "", "", errors.Errorf("invalid host %q: %v", host, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", "", errors.Errorf("invalid host %q: scheme must be http, https or unix", host)
+	}
+	if u.Host == "" {
+		return "", "", errors.Errorf("invalid host %q: missing hostname", host)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return "", "", errors.Errorf("invalid host %q: query and fragment are not allowed", host)
+	}
+
+	// a non-root path is kept as prefix of the API routes
+	return strings.TrimRight(u.String(), "/"), "", nil
+}
+
+func newHTTPClient(settings transportSettings) *http.Client {
+	var transport http.RoundTripper = newHTTPTransport(settings)
+	if settings.Trace != nil {
+		transport = settings.Trace.wrap(transport)
+	}
+	if settings.TokenCommand != nil {
+		transport = settings.TokenCommand.wrap(transport)
+	}
+	return &http.Client{Transport: transport}
+}
+
+func newHTTPTransport(settings transportSettings) *http.Transport {
+	keepAlive := settings.KeepAlive
+	if keepAlive == 0 {
+		keepAlive = 30 * time.Second
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: keepAlive,
+	}
+
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		DisableCompression:    settings.DisableCompression,
+	}
+
+	if settings.DisableHTTP2 {
+		// a non-nil empty map prevents the upgrade to HTTP/2 on TLS connections
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	if settings.SocketPath != "" {
+		transport.Proxy = nil
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", settings.SocketPath)
+		}
+	}
+
+	if settings.SkipCertVerification {
+		transport.TLSClientConfig = &tls.Config{
+			InsecureSkipVerify: true,
+		}
+	}
+
+	return transport
+}
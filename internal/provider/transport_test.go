@@ -0,0 +1,94 @@
+// Copyright 2024 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provider
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTsuruHost(t *testing.T) {
+	tests := []struct {
+		host       string
+		basePath   string
+		socketPath string
+		err        string
+	}{
+		{host: "https://tsuru.example.com", basePath: "https://tsuru.example.com"},
+		{host: "https://tsuru.example.com/", basePath: "https://tsuru.example.com"},
+		{host: "tsuru.example.com:8080", basePath: "http://tsuru.example.com:8080"},
+		{host: "https://proxy.example.com/tsuru/api/", basePath: "https://proxy.example.com/tsuru/api"},
+		{host: "unix:///var/run/tsuru.sock", basePath: unixSocketBasePath, socketPath: "/var/run/tsuru.sock"},
+		{host: "unix://tsuru.sock", err: "unix socket path must be absolute"},
+		{host: "ftp://tsuru.example.com", err: "scheme must be http, https or unix"},
+		{host: "https://", err: "missing hostname"},
+		{host: "https://tsuru.example.com/?debug=1", err: "query and fragment are not allowed"},
+		{host: "https://tsuru example.com", err: "invalid host"},
+	}
+
+	for _, tt := range tests {
+		basePath, socketPath, err := parseTsuruHost(tt.host)
+		if tt.err != "" {
+			assert.ErrorContains(t, err, tt.err, tt.host)
+			continue
+		}
+		require.NoError(t, err, tt.host)
+		assert.Equal(t, tt.basePath, basePath, tt.host)
+		assert.Equal(t, tt.socketPath, socketPath, tt.host)
+	}
+}
+
+func TestNewHTTPTransportUnixSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "tsuru.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.URL.Path))
+		}),
+	}
+	go server.Serve(listener)
+	defer server.Close()
+
+	client := &http.Client{Transport: newHTTPTransport(transportSettings{SocketPath: socketPath})}
+	resp, err := client.Get(unixSocketBasePath + "/1.0/info")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "/1.0/info", string(body))
+}
+
+func TestNewHTTPTransportHTTP2AndCompression(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto + " " + r.Header.Get("Accept-Encoding")))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	get := func(settings transportSettings) string {
+		settings.SkipCertVerification = true
+		resp, err := newHTTPClient(settings).Get(server.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	assert.Equal(t, "HTTP/2.0 gzip", get(transportSettings{}))
+	assert.Equal(t, "HTTP/1.1 gzip", get(transportSettings{DisableHTTP2: true}))
+	assert.Equal(t, "HTTP/2.0 ", get(transportSettings{DisableCompression: true}))
+}
@@ -6,9 +6,11 @@ package provider
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -32,12 +34,87 @@ func (e *MaxRetriesError) Error() string {
 	return e.Message
 }
 
+// rawAPIError is returned by requests made to tsuru API without the
+// generated client, like deploys and log streams.
+type rawAPIError struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *rawAPIError) Error() string {
+	return fmt.Sprintf("status code: %d, message: %s", e.StatusCode, string(e.Body))
+}
+
+type retrySettings struct {
+	MaxAttempts int
+	Delay       time.Duration
+}
+
+func retrySchema(description string) *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Description: description,
+		Optional:    true,
+		MaxItems:    1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"max_attempts": {
+					Type:        schema.TypeInt,
+					Description: "Maximum number of attempts, use 0 to retry until the operation times out",
+					Optional:    true,
+					Default:     0,
+				},
+				"delay": {
+					Type:         schema.TypeString,
+					Description:  "Minimum time to wait between attempts, like `5s` or `1m`",
+					Optional:     true,
+					ValidateFunc: validateDuration,
+				},
+			},
+		},
+	}
+}
+
+func validateDuration(i interface{}, k string) ([]string, []error) {
+	v, ok := i.(string)
+	if !ok {
+		return nil, []error{errors.Errorf("expected type of %s to be string", k)}
+	}
+	if _, err := time.ParseDuration(v); err != nil {
+		return nil, []error{errors.Errorf("invalid duration %q for %s: %v", v, k, err)}
+	}
+	return nil, nil
+}
+
+func expandRetrySettings(raw interface{}) (*retrySettings, bool) {
+	items, ok := raw.([]interface{})
+	if !ok || len(items) == 0 || items[0] == nil {
+		return nil, false
+	}
+
+	item := items[0].(map[string]interface{})
+	settings := &retrySettings{}
+	if maxAttempts, ok := item["max_attempts"].(int); ok {
+		settings.MaxAttempts = maxAttempts
+	}
+	if delay, ok := item["delay"].(string); ok && delay != "" {
+		// already checked by validateDuration
+		settings.Delay, _ = time.ParseDuration(delay)
+	}
+
+	return settings, true
+}
+
 func isNotFoundError(err error) bool {
 	if err == nil {
 		return false
 	}
 	openAPIError, ok := err.(tsuru_client.GenericOpenAPIError)
-	return ok && openAPIError.StatusCode() == http.StatusNotFound
+	if ok {
+		return openAPIError.StatusCode() == http.StatusNotFound
+	}
+	var rawError *rawAPIError
+	return errors.As(err, &rawError) && rawError.StatusCode == http.StatusNotFound
 }
 
 func isRetryableError(err []byte) bool {
@@ -45,19 +122,51 @@ func isRetryableError(err []byte) bool {
 	return strings.Contains(e, "event locked")
 }
 
-func tsuruRetry(ctx context.Context, d *schema.ResourceData, f func() error) error {
+func isRetryableAPIError(err error) bool {
+	var apiError tsuru_client.GenericOpenAPIError
+	if errors.As(err, &apiError) {
+		return isRetryableError(apiError.Body())
+	}
+	var rawError *rawAPIError
+	if errors.As(err, &rawError) {
+		return isRetryableError(rawError.Body)
+	}
+	return false
+}
+
+// tsuruRetry retries f while tsuru reports the target as locked by another
+// operation, using the retry block of the resource when it has one and the
+// provider retry settings otherwise.
+func tsuruRetry(ctx context.Context, provider *tsuruProvider, d *schema.ResourceData, f func() error) error {
+	settings := provider.Retry
+	if resourceSettings, ok := expandRetrySettings(d.Get("retry")); ok {
+		settings = *resourceSettings
+	}
+
+	attempts := 0
 	return resource.RetryContext(ctx, d.Timeout(schema.TimeoutCreate), func() *resource.RetryError {
+		attempts++
 		err := f()
-		if err != nil {
-			var apiError tsuru_client.GenericOpenAPIError
-			if errors.As(err, &apiError) {
-				if isRetryableError(apiError.Body()) {
-					return resource.RetryableError(err)
-				}
-			}
+		if err == nil {
+			return nil
+		}
+		if !isRetryableAPIError(err) {
 			return resource.NonRetryableError(err)
 		}
-		return nil
+		if settings.MaxAttempts > 0 && attempts >= settings.MaxAttempts {
+			return resource.NonRetryableError(&MaxRetriesError{
+				Message: fmt.Sprintf("giving up after %d attempts: %v", attempts, err),
+				Meta:    err,
+			})
+		}
+		if settings.Delay > 0 {
+			select {
+			case <-ctx.Done():
+				return resource.NonRetryableError(ctx.Err())
+			case <-time.After(settings.Delay):
+			}
+		}
+		return resource.RetryableError(err)
 	})
 }
 
@@ -160,3 +269,37 @@ func checkProcessesListsChanges(oldProcesses []tsuru_client.AppProcess, newProce
 
 	return onlyInOldList, onlyInNewList, inBoth
 }
+
+// normalizeTags applies the same rules used by tsuru API when storing tags:
+// surrounding spaces are trimmed, empty and duplicated tags are dropped.
+func normalizeTags(tags []string) []string {
+	normalized := []string{}
+	used := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || used[tag] {
+			continue
+		}
+		used[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// reconcileTags keeps the configured tags when they are equivalent to the
+// tags returned by the API, avoiding perpetual diffs caused by server side
+// normalization, which trims and dedupes tags but keeps their case.
+func reconcileTags(configured []string, remote []string) []string {
+	normalizedConfigured := normalizeTags(configured)
+	if len(normalizedConfigured) != len(remote) {
+		return remote
+	}
+
+	for i := range remote {
+		if normalizedConfigured[i] != remote[i] {
+			return remote
+		}
+	}
+
+	return configured
+}
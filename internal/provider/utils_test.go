@@ -1,9 +1,13 @@
 package provider
 
 import (
+	"context"
+	"errors"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	tsuru_client "github.com/tsuru/go-tsuruclient/pkg/tsuru"
 )
 
@@ -597,3 +601,88 @@ func TestCheckProcessesListsChangesWhenNewEmpty(t *testing.T) {
 	assert.Equal(t, expectedNew, new)
 	assert.Equal(t, expectedBoth, both)
 }
+
+func TestNormalizeTags(t *testing.T) {
+	assert.Equal(t, []string{"tagA", "tagB"}, normalizeTags([]string{" tagA", "tagB ", "", "tagA"}))
+	assert.Equal(t, []string{}, normalizeTags(nil))
+}
+
+func TestReconcileTagsWhenServerNormalizes(t *testing.T) {
+	configured := []string{"TagA", " Team-X ", "TagA", ""}
+	remote := []string{"TagA", "Team-X"}
+
+	assert.Equal(t, configured, reconcileTags(configured, remote))
+}
+
+func TestReconcileTagsWhenCaseChanged(t *testing.T) {
+	configured := []string{"Team", "Tier-1"}
+	remote := []string{"team", "Tier-1"}
+
+	assert.Equal(t, remote, reconcileTags(configured, remote))
+}
+
+func TestReconcileTagsWhenRemoteChanged(t *testing.T) {
+	configured := []string{"TagA", "TagB"}
+	remote := []string{"TagA", "TagC"}
+
+	assert.Equal(t, remote, reconcileTags(configured, remote))
+	assert.Equal(t, []string{"TagA"}, reconcileTags(configured, []string{"TagA"}))
+}
+
+func TestTsuruRetryUsesResourceSettings(t *testing.T) {
+	provider := &tsuruProvider{Retry: retrySettings{MaxAttempts: 5}}
+	d := schema.TestResourceDataRaw(t, resourceTsuruApplicationDeploy().Schema, map[string]interface{}{
+		"app":   "app01",
+		"image": "myrepo/app01:0.1.0",
+		"retry": []interface{}{map[string]interface{}{
+			"max_attempts": 2,
+			"delay":        "1ms",
+		}},
+	})
+
+	attempts := 0
+	err := tsuruRetry(context.Background(), provider, d, func() error {
+		attempts++
+		return &rawAPIError{StatusCode: 409, Body: []byte("event locked: app01")}
+	})
+
+	var maxRetriesError *MaxRetriesError
+	require.True(t, errors.As(err, &maxRetriesError), "unexpected error: %v", err)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestTsuruRetryFallbackToProviderSettings(t *testing.T) {
+	provider := &tsuruProvider{Retry: retrySettings{MaxAttempts: 1}}
+	d := schema.TestResourceDataRaw(t, resourceTsuruPoolConstraint().Schema, map[string]interface{}{
+		"pool_expr": "*",
+		"field":     "router",
+	})
+
+	attempts := 0
+	err := tsuruRetry(context.Background(), provider, d, func() error {
+		attempts++
+		return &rawAPIError{StatusCode: 409, Body: []byte("event locked: pool")}
+	})
+
+	var maxRetriesError *MaxRetriesError
+	require.True(t, errors.As(err, &maxRetriesError), "unexpected error: %v", err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestTsuruRetryNonRetryableError(t *testing.T) {
+	provider := &tsuruProvider{}
+	d := schema.TestResourceDataRaw(t, resourceTsuruApplicationDeploy().Schema, map[string]interface{}{
+		"app":   "app01",
+		"image": "myrepo/app01:0.1.0",
+	})
+
+	attempts := 0
+	err := tsuruRetry(context.Background(), provider, d, func() error {
+		attempts++
+		return &rawAPIError{StatusCode: 400, Body: []byte("invalid image")}
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid image")
+	assert.Equal(t, 1, attempts)
+}